@@ -0,0 +1,97 @@
+package mt940
+
+import (
+	"strings"
+
+	"golang.org/x/text/currency"
+)
+
+// Account of a :25: identification, banks holding an account in several
+// currencies append the currency of the sub-account to the identification,
+// eg. NL08DEUT0319809633EUR or UBRTHUHB/123456789150ABCDEF002/HUF
+type Account struct {
+	Base     string
+	Currency currency.Unit // Zero when the identification has no currency suffix
+}
+
+// Splits the currency suffix off an account identification. The suffix is an
+// ISO 4217 code following a digit or a /, so identifications ending in
+// letters otherwise aren't taken for sub-accounts
+func ParseAccount(id string) Account {
+	id = strings.TrimSpace(id)
+	if len(id) < 4 {
+		return Account{Base: id}
+	}
+	code, rest := id[len(id)-3:], id[:len(id)-3]
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return Account{Base: id}
+		}
+	}
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return Account{Base: id}
+	}
+	switch c := rest[len(rest)-1]; {
+	case c == '/':
+		rest = rest[:len(rest)-1]
+	case c < '0' || c > '9':
+		return Account{Base: id}
+	}
+	return Account{Base: rest, Currency: unit}
+}
+
+func (a Account) HasCurrency() bool {
+	return a.Currency != currency.Unit{}
+}
+
+func (tr *Transaction) Account() Account {
+	return ParseAccount(tr.AccountIdentification)
+}
+
+// Statements of the currency sub-accounts of one account
+type ParentAccount struct {
+	Base        string
+	SubAccounts []AccountStatements // By :25: in order of appearance
+}
+
+// Groups statements by the base of their accounts, in order of each base's
+// first statement. Accounts without a currency suffix are their own parent
+func ByParentAccount(statements []Transaction) []ParentAccount {
+	var parents []ParentAccount
+	index := make(map[string]int)
+	for _, a := range ByAccount(statements) {
+		base := ParseAccount(a.Account).Base
+		i, ok := index[base]
+		if !ok {
+			i = len(parents)
+			index[base] = i
+			parents = append(parents, ParentAccount{Base: base})
+		}
+		parents[i].SubAccounts = append(parents[i].SubAccounts, a)
+	}
+	return parents
+}
+
+// Closing balance of the most recent statement of each sub-account by
+// currency code, the :62M: of a statement split into pages when the final
+// page is missing
+func (p *ParentAccount) Balances() map[string]Balance {
+	balances := make(map[string]Balance, len(p.SubAccounts))
+	for _, a := range p.SubAccounts {
+		tr := &a.Statements[len(a.Statements)-1]
+		b := tr.FinalClosingBalance
+		if b.Timestamp.Time == nil && tr.IntermediateClosingBalance.Timestamp.Time != nil {
+			b = tr.IntermediateClosingBalance
+		}
+		code := balanceCode(&b)
+		if code == "" {
+			if !tr.Account().HasCurrency() {
+				continue
+			}
+			code = tr.Account().Currency.String()
+		}
+		balances[code] = b
+	}
+	return balances
+}
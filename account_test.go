@@ -0,0 +1,64 @@
+package mt940
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/currency"
+)
+
+func TestParseAccount(t *testing.T) {
+	tests := []struct {
+		id   string
+		want Account
+	}{
+		{"NL08DEUT0319809633EUR", Account{"NL08DEUT0319809633", currency.EUR}},
+		{"UBRTHUHB/123456789150ABCDEF002/HUF", Account{"UBRTHUHB/123456789150ABCDEF002", currency.MustParseISO("HUF")}},
+		{"NL08DEUT0319809633", Account{"NL08DEUT0319809633", currency.Unit{}}},
+		{"PL02236000050000004550212345", Account{"PL02236000050000004550212345", currency.Unit{}}},
+		{"0123456789ABC", Account{"0123456789ABC", currency.Unit{}}},
+		{"ACCOUNTEUR", Account{"ACCOUNTEUR", currency.Unit{}}},
+		{"EUR", Account{"EUR", currency.Unit{}}},
+		{"", Account{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if got := ParseAccount(tt.id); got != tt.want {
+				t.Errorf("ParseAccount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestByParentAccount(t *testing.T) {
+	input := ":20:EUR1\n:25:NL08DEUT0319809633EUR\n:60F:C171011EUR1,00\n:62F:C171011EUR1,00\n-\n" +
+		":20:OTHER\n:25:DK0230003617012345\n:60F:C171011DKK5,00\n:62F:C171011DKK5,00\n-\n" +
+		":20:USD\n:25:NL08DEUT0319809633USD\n:60F:C171011USD2,00\n:62F:C171011USD2,00\n-\n" +
+		":20:EUR2\n:25:NL08DEUT0319809633EUR\n:60F:C171012EUR1,00\n:62F:C171012EUR3,00\n"
+	got, err := (&Transactions{}).Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parents := ByParentAccount(got)
+	if len(parents) != 2 || parents[0].Base != "NL08DEUT0319809633" || parents[1].Base != "DK0230003617012345" {
+		t.Fatalf("ByParentAccount() = %v", parents)
+	}
+	var subAccounts []string
+	for _, a := range parents[0].SubAccounts {
+		subAccounts = append(subAccounts, a.Account)
+	}
+	if !reflect.DeepEqual(subAccounts, []string{"NL08DEUT0319809633EUR", "NL08DEUT0319809633USD"}) {
+		t.Errorf("SubAccounts = %v", subAccounts)
+	}
+
+	balances := make(map[string]Amount)
+	for code, b := range parents[0].Balances() {
+		balances[code] = b.Amount
+	}
+	want := map[string]Amount{"EUR": NewAmount(300), "USD": NewAmount(200)}
+	if !reflect.DeepEqual(balances, want) {
+		t.Errorf("ParentAccount.Balances() = %v, want %v", balances, want)
+	}
+}
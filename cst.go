@@ -0,0 +1,145 @@
+package mt940
+
+import (
+	"sort"
+	"strings"
+)
+
+type NodeKind int
+
+const (
+	TagNode       NodeKind = iota // A tag with its continuation lines
+	SeparatorNode                 // A message separator line, eg. - or -}
+	TriviaNode                    // Blank lines, headers and other text outside of tags
+)
+
+// Concrete syntax tree of an input, its nodes cover every byte of it in order
+// so nothing the bank sent is lost
+type CST struct {
+	Nodes []Node
+}
+
+type Node struct {
+	Kind   NodeKind
+	ID     string // Tag id of tag nodes
+	Raw    string // Exact bytes of the input including line terminators
+	Pos    Position
+	Fields []Field // Fields of tag nodes that parsed, in order of appearance
+}
+
+// Value of a tag field and where it came from, a field spanning lines keeps
+// the line terminators in its source span but not in Value
+type Field struct {
+	Name  string
+	Value string
+	Pos   Position
+}
+
+// Builds the tree of data with its tags parsed by registry, DefaultRegistry
+// when nil. Tags that don't parse have no fields
+func NewCST(file string, data []byte, registry *Registry) *CST {
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+	lines := splitLines(data)
+	classifyLines(lines, registry.known)
+	tokens := tokenizeLines(lines)
+	index := newLineIndex(file, data)
+
+	cst, nodes := newCST(index, lines, tokens, data, nil)
+	for i, tok := range tokens {
+		if tag, ok := registry.lookup(tok.id); ok {
+			if result, spans, err := tag.parse(tok.value); err == nil {
+				cst.Nodes[nodes[i]].addFields(index, tok, result, spans)
+			}
+		}
+	}
+	return cst
+}
+
+// Builds the nodes of the tokens and the lines between them, returning the
+// index of each token's node. The text of the nodes is taken from raw, the
+// input before transcoding, through offsets which are nil when the input
+// wasn't changed
+func newCST(index *lineIndex, lines []line, tokens []token, raw []byte, offsets []int) (*CST, []int) {
+	cst := &CST{}
+	nodes := make([]int, len(tokens))
+	source := func(start, end int) string {
+		if offsets == nil {
+			return string(raw[start:end])
+		}
+		return string(raw[offsets[start]:offsets[end]])
+	}
+	next := 0
+	for _, l := range lines {
+		switch {
+		case next < len(tokens) && l.start == tokens[next].start:
+			tok := tokens[next]
+			nodes[next] = len(cst.Nodes)
+			cst.Nodes = append(cst.Nodes, Node{
+				Kind: TagNode,
+				ID:   tok.id,
+				Raw:  source(tok.start, tok.end),
+				Pos:  index.position(tok.start, tok.end),
+			})
+			next++
+		case len(cst.Nodes) > 0 && cst.Nodes[len(cst.Nodes)-1].Kind == TagNode && l.start < cst.Nodes[len(cst.Nodes)-1].Pos.End:
+			// Continuation of the tag
+		default:
+			kind := TriviaNode
+			if l.kind == separatorLine {
+				kind = SeparatorNode
+			}
+			cst.Nodes = append(cst.Nodes, Node{
+				Kind: kind,
+				Raw:  source(l.start, l.end),
+				Pos:  index.position(l.start, l.end),
+			})
+		}
+	}
+	return cst, nodes
+}
+
+// Adds the fields of a parsed tag, spans are offsets into the token's value
+func (n *Node) addFields(index *lineIndex, tok token, result TagResults, spans map[string][2]int) {
+	n.Fields = n.Fields[:0]
+	for name, span := range spans {
+		if span[0] == span[1] {
+			continue
+		}
+		start, end := tok.offsets[span[0]], tok.offsets[span[1]-1]+1
+		n.Fields = append(n.Fields, Field{name, result[name], index.position(start, end)})
+	}
+	sort.Slice(n.Fields, func(i, j int) bool {
+		return n.Fields[i].Pos.Offset < n.Fields[j].Pos.Offset
+	})
+}
+
+// Reproduces the input the tree was built from
+func (c *CST) String() string {
+	var b strings.Builder
+	for _, n := range c.Nodes {
+		b.WriteString(n.Raw)
+	}
+	return b.String()
+}
+
+// Returns the node containing the byte at offset, or nil
+func (c *CST) NodeAt(offset int) *Node {
+	i := sort.Search(len(c.Nodes), func(i int) bool {
+		return c.Nodes[i].Pos.End > offset
+	})
+	if i == len(c.Nodes) || c.Nodes[i].Pos.Offset > offset {
+		return nil
+	}
+	return &c.Nodes[i]
+}
+
+func (n *Node) Field(name string) (Field, bool) {
+	for _, f := range n.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
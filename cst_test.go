@@ -0,0 +1,146 @@
+package mt940
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewCST_RoundTrip(t *testing.T) {
+	files, err := filepath.Glob("*/*.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file, ".go") {
+			continue
+		}
+		t.Run(file, func(t *testing.T) {
+			data := must(os.ReadFile(file))
+			cst := NewCST(file, data, nil)
+			if got := cst.String(); got != string(data) {
+				t.Errorf("CST.String() doesn't reproduce the input, got %q", got)
+			}
+			end := 0
+			for _, n := range cst.Nodes {
+				if n.Pos.Offset != end || n.Raw != string(data[n.Pos.Offset:n.Pos.End]) {
+					t.Fatalf("node %v at %v doesn't continue at offset %v", n.ID, n.Pos, end)
+				}
+				end = n.Pos.End
+			}
+		})
+	}
+}
+
+func TestNewCST_Fields(t *testing.T) {
+	input := "{1:F01}\r\n:20:REF\r\n:61:1112021202D43,6NTRFNONREF//B1\r\n  card payment\r\n" +
+		":86:first line\r\n\r\nsecond line\r\n\r\n-}\r\n"
+	cst := NewCST("test.sta", []byte(input), nil)
+	if cst.String() != input {
+		t.Fatalf("CST.String() = %q, want the input", cst.String())
+	}
+
+	var kinds []NodeKind
+	for _, n := range cst.Nodes {
+		kinds = append(kinds, n.Kind)
+	}
+	want := []NodeKind{TriviaNode, TagNode, TagNode, TagNode, TriviaNode, SeparatorNode}
+	if !equalKinds(kinds, want) {
+		t.Fatalf("node kinds = %v, want %v", kinds, want)
+	}
+
+	tests := []struct {
+		offset int
+		field  string
+		value  string
+		source string
+		line   int
+	}{
+		{12, "transaction_reference", "REF", "REF", 2},
+		{20, "amount", "43,6", "43,6", 3},
+		{20, "bank_reference", "B1", "B1", 3},
+		{20, "extra_details", "  card payment", "  card payment", 4},
+		{70, "transaction_details", "first line\n\nsecond line", "first line\r\n\r\nsecond line", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			node := cst.NodeAt(tt.offset)
+			if node == nil {
+				t.Fatalf("NodeAt(%v) = nil", tt.offset)
+			}
+			f, ok := node.Field(tt.field)
+			if !ok {
+				t.Fatalf("Node.Field(%v) missing in %v", tt.field, node.Fields)
+			}
+			if f.Value != tt.value || input[f.Pos.Offset:f.Pos.End] != tt.source || f.Pos.Line != tt.line {
+				t.Errorf("Node.Field() = %q from %q on line %v, want %q from %q on line %v",
+					f.Value, input[f.Pos.Offset:f.Pos.End], f.Pos.Line, tt.value, tt.source, tt.line)
+			}
+		})
+	}
+	if node := cst.NodeAt(len(input)); node != nil {
+		t.Errorf("NodeAt(end) = %v, want nil", node)
+	}
+}
+
+func TestTransactions_Parse_CST(t *testing.T) {
+	input := must(os.ReadFile("ASNB/0708271685_09022020_164516.940.txt"))
+	tr := &Transactions{Lenient: true}
+	got, err := tr.Parse(strings.NewReader(string(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.CST.String() != string(input) {
+		t.Error("CST.String() doesn't reproduce the input")
+	}
+
+	// The model's positions lead to the source of the fields
+	node := tr.CST.NodeAt(got[0].Positions["60F"].Offset)
+	if node == nil || node.ID != "60F" {
+		t.Fatalf("NodeAt() = %v, want the :60F: node", node)
+	}
+	if f, ok := node.Field("currency"); !ok || f.Value != got[0].FinalOpeningBalance.code {
+		t.Errorf("Node.Field(currency) = %v, want %v", f, got[0].FinalOpeningBalance.code)
+	}
+}
+
+func TestTransactions_Parse_CST_Transformed(t *testing.T) {
+	raiffeisen := must(os.ReadFile("self-provided/raiffeisen-cmi.sta"))
+	tests := []struct {
+		name  string
+		tr    *Transactions
+		input string
+	}{
+		{"encoding", &Transactions{AutoDetectEncoding: true}, string(raiffeisen)},
+		{"sanitize", &Transactions{Sanitize: true, SanitizeReplacement: '?'}, ":20:R\x00EF\n:60F:C171011EUR1,00\n:86:Illet\xe9k\n:62F:C171011EUR1,00\n-\n"},
+		{"strip", &Transactions{Sanitize: true}, "\x02:20:R\x00EF\n:60F:C171011EUR1,00\n:62F:C171011EUR1,00\n-\x03"},
+		{"encoding and sanitize", &Transactions{AutoDetectEncoding: true, Sanitize: true}, "\x01" + string(raiffeisen)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.tr.Parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.tr.CST.String() != tt.input {
+				t.Errorf("CST.String() = %q, want the original input", tt.tr.CST.String())
+			}
+			if node := tt.tr.CST.NodeAt(got[0].Positions["60F"].Offset); node == nil || node.ID != "60F" {
+				t.Errorf("NodeAt() = %v, want the :60F: node", node)
+			}
+		})
+	}
+}
+
+func equalKinds(a, b []NodeKind) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+package mt940
+
+import (
+	"fmt"
+
+	"golang.org/x/text/currency"
+)
+
+var ErrNoEntry = NewParseError("no entry at index")
+
+// Number and sum of the debit or credit entries of a statement (:90D:/:90C:)
+type EntrySummary struct {
+	Count    int
+	Currency currency.Unit
+	Amount
+}
+
+// Amount with the sign of its status, credits are positive
+func signed(status string, amt Amount) int64 {
+	switch status {
+	case "D", "RC":
+		return -amt.int64
+	}
+	return amt.int64
+}
+
+func (e *Entry) isDebit() bool {
+	return signed(e.Status, Amount{1}) < 0
+}
+
+func (tr *Transaction) entry(i int) (*Entry, error) {
+	if i < 0 || i >= len(tr.Entries) {
+		return nil, fmt.Errorf("%w %v, statement has %v entries", ErrNoEntry, i, len(tr.Entries))
+	}
+	return &tr.Entries[i], nil
+}
+
+// Appends an entry and recalculates the statement
+func (tr *Transaction) AddEntry(e Entry) {
+	tr.Entries = append(tr.Entries, e)
+	tr.Recalculate()
+}
+
+// Inserts an entry before entry i, or at the end when i is the number of
+// entries, and recalculates the statement
+func (tr *Transaction) InsertEntry(i int, e Entry) error {
+	if i != len(tr.Entries) {
+		if _, err := tr.entry(i); err != nil {
+			return err
+		}
+	}
+	tr.Entries = append(tr.Entries[:i], append([]Entry{e}, tr.Entries[i:]...)...)
+	tr.Recalculate()
+	return nil
+}
+
+func (tr *Transaction) RemoveEntry(i int) error {
+	if _, err := tr.entry(i); err != nil {
+		return err
+	}
+	tr.Entries = append(tr.Entries[:i], tr.Entries[i+1:]...)
+	tr.Recalculate()
+	return nil
+}
+
+// Moves entry from to index to, shifting the entries in between
+func (tr *Transaction) MoveEntry(from, to int) error {
+	e, err := tr.entry(from)
+	if err != nil {
+		return err
+	}
+	if _, err := tr.entry(to); err != nil {
+		return err
+	}
+	moved := *e
+	tr.Entries = append(tr.Entries[:from], tr.Entries[from+1:]...)
+	tr.Entries = append(tr.Entries[:to], append([]Entry{moved}, tr.Entries[to:]...)...)
+	tr.Recalculate()
+	return nil
+}
+
+// Changes the debit/credit mark and amount of entry i
+func (tr *Transaction) SetEntryAmount(i int, status string, amt Amount) error {
+	e, err := tr.entry(i)
+	if err != nil {
+		return err
+	}
+	e.Status, e.Amount = status, amt
+	e.decimals = 0
+	tr.Recalculate()
+	return nil
+}
+
+// Changes the :86: details of entry i, lines are separated by LF
+func (tr *Transaction) SetEntryDetails(i int, details string) error {
+	e, err := tr.entry(i)
+	if err != nil {
+		return err
+	}
+	e.TransactionDetails = details
+	tr.Recalculate()
+	return nil
+}
+
+// Brings the closing balance, the entry sums and the most recent statement
+// line in line with the entries after editing them. Pages of a statement
+// split by :28C: start from their :60M: and close with their :62M: instead
+func (tr *Transaction) Recalculate() {
+	opening := &tr.FinalOpeningBalance
+	if opening.Timestamp.Time == nil && tr.IntermediateOpeningBalance.Timestamp.Time != nil {
+		opening = &tr.IntermediateOpeningBalance
+	}
+	closing := &tr.FinalClosingBalance
+	if closing.Timestamp.Time == nil && tr.IntermediateClosingBalance.Timestamp.Time != nil {
+		closing = &tr.IntermediateClosingBalance
+	}
+
+	total := signed(opening.Status, opening.Amount)
+	var debits, credits EntrySummary
+	for i := range tr.Entries {
+		e := &tr.Entries[i]
+		total += signed(e.Status, e.Amount)
+		if e.isDebit() {
+			debits.Count++
+			debits.int64 += e.int64
+		} else {
+			credits.Count++
+			credits.int64 += e.int64
+		}
+	}
+
+	closing.Status, closing.Amount = "C", Amount{total}
+	if total < 0 {
+		closing.Status, closing.Amount = "D", Amount{-total}
+	}
+	closing.decimals = 0
+	if closing.code == "" {
+		closing.code, closing.Currency = opening.code, opening.Currency
+	}
+	if closing.Timestamp.Time == nil {
+		closing.Timestamp = opening.Timestamp
+	}
+
+	if tr.DebitEntries != nil {
+		debits.Currency = tr.DebitEntries.Currency
+		*tr.DebitEntries = debits
+	}
+	if tr.CreditEntries != nil {
+		credits.Currency = tr.CreditEntries.Currency
+		*tr.CreditEntries = credits
+	}
+
+	tr.StatementLine = StatementLine{}
+	tr.TransactionDetails = ""
+	if e := tr.currentEntry(); e != nil {
+		tr.StatementLine = e.StatementLine
+		tr.TransactionDetails = e.TransactionDetails
+	}
+}
@@ -0,0 +1,96 @@
+package mt940
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const editInput = ":20:REF\n:25:NL08DEUT0319809633\n:28C:1/1\n:60F:C171011EUR100,00\n" +
+	":61:171011D20,00NTRFNONREF\n:86:first\n" +
+	":61:171011C5,50NTRFNONREF\n:86:second\n" +
+	":61:171011RD1,00NTRFNONREF\n:86:third\n" +
+	":62F:C171011EUR86,50\n"
+
+func parseEditInput(t *testing.T) *Transaction {
+	got, err := (&Transactions{}).Parse(strings.NewReader(editInput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &got[0]
+}
+
+func entryDetails(tr *Transaction) string {
+	var details []string
+	for _, e := range tr.Entries {
+		details = append(details, e.TransactionDetails)
+	}
+	return strings.Join(details, " ")
+}
+
+func TestTransaction_Edit(t *testing.T) {
+	added := Entry{StatementLine: StatementLine{Status: "D", Amount: NewAmount(150000), TransactionTypeID: "NCHG"}, TransactionDetails: "added"}
+	tests := []struct {
+		name    string
+		edit    func(tr *Transaction) error
+		closing string
+		details string
+		wantErr error
+	}{
+		{"unchanged", func(tr *Transaction) error { tr.Recalculate(); return nil }, "C86,50", "first second third", nil},
+		{"add", func(tr *Transaction) error { tr.AddEntry(added); return nil }, "D1413,50", "first second third added", nil},
+		{"insert", func(tr *Transaction) error { return tr.InsertEntry(1, added) }, "D1413,50", "first added second third", nil},
+		{"insert at end", func(tr *Transaction) error { return tr.InsertEntry(3, added) }, "D1413,50", "first second third added", nil},
+		{"remove", func(tr *Transaction) error { return tr.RemoveEntry(0) }, "C106,50", "second third", nil},
+		{"move", func(tr *Transaction) error { return tr.MoveEntry(0, 2) }, "C86,50", "second third first", nil},
+		{"move back", func(tr *Transaction) error { return tr.MoveEntry(2, 0) }, "C86,50", "third first second", nil},
+		{"amount", func(tr *Transaction) error { return tr.SetEntryAmount(1, "D", NewAmount(550)) }, "C75,50", "first second third", nil},
+		{"page", func(tr *Transaction) error {
+			// A page between others opens with :60M: and closes with :62M:
+			page := strings.NewReplacer(":28C:1/1", ":28C:1/2", ":60F:", ":60M:", ":62F:", ":62M:").Replace(editInput)
+			got, err := (&Transactions{}).Parse(strings.NewReader(page))
+			if err != nil {
+				return err
+			}
+			*tr = got[0]
+			return tr.SetEntryAmount(1, "D", NewAmount(550))
+		}, "C75,50", "first second third", nil},
+		{"details", func(tr *Transaction) error { return tr.SetEntryDetails(2, "changed") }, "C86,50", "first second changed", nil},
+		{"out of range", func(tr *Transaction) error { return tr.RemoveEntry(3) }, "C86,50", "first second third", ErrNoEntry},
+		{"move out of range", func(tr *Transaction) error { return tr.MoveEntry(0, -1) }, "C86,50", "first second third", ErrNoEntry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := parseEditInput(t)
+			if err := tt.edit(tr); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("edit error = %v, want %v", err, tt.wantErr)
+			}
+			c := tr.FinalClosingBalance
+			if c.Timestamp.Time == nil {
+				c = tr.IntermediateClosingBalance
+			}
+			if closing := c.Status + c.Decimal(); closing != tt.closing {
+				t.Errorf("closing balance = %v, want %v", closing, tt.closing)
+			}
+			if details := entryDetails(tr); details != tt.details {
+				t.Errorf("entries = %v, want %v", details, tt.details)
+			}
+			last := tr.Entries[len(tr.Entries)-1]
+			if tr.StatementLine != last.StatementLine || tr.TransactionDetails != last.TransactionDetails {
+				t.Errorf("StatementLine = %v, want the last entry", tr.StatementLine)
+			}
+		})
+	}
+}
+
+func TestTransaction_Recalculate_Summaries(t *testing.T) {
+	tr := parseEditInput(t)
+	tr.DebitEntries, tr.CreditEntries = &EntrySummary{}, &EntrySummary{}
+	tr.Recalculate()
+	if d := tr.DebitEntries; d.Count != 1 || d.Amount != NewAmount(2000) {
+		t.Errorf("DebitEntries = %+v, want 1 entry of 20,00", d)
+	}
+	if c := tr.CreditEntries; c.Count != 2 || c.Amount != NewAmount(650) {
+		t.Errorf("CreditEntries = %+v, want 2 entries of 6,50", c)
+	}
+}
@@ -0,0 +1,129 @@
+package mt940
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	xunicode "golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Encodings that can be detected, in order of preference when they score
+// the same
+var Encodings = []struct {
+	Name string
+	encoding.Encoding
+}{
+	{"utf-8", xunicode.UTF8},
+	{"iso-8859-1", charmap.ISO8859_1},
+	{"iso-8859-2", charmap.ISO8859_2},
+	{"windows-1250", charmap.Windows1250},
+	{"windows-1251", charmap.Windows1251},
+	{"ibm852", charmap.CodePage852},
+}
+
+// Letters of the languages the encodings are used for, decoding into these
+// makes an encoding more likely
+const commonLetters = "äöüßÄÖÜéèêëáàâíìîóòôúùûçñåøæœÉÈÁÀÍÓÚÇÑÅØÆ" +
+	"őűŐŰąęłńśźżĄĘŁŃŚŹŻčďěňřšťůžČĎĚŇŘŠŤŮŽ"
+
+func LookupEncoding(name string) encoding.Encoding {
+	for _, e := range Encodings {
+		if strings.EqualFold(e.Name, name) {
+			return e.Encoding
+		}
+	}
+	return nil
+}
+
+// Scores how plausible the non-ASCII characters of decoded text are, runs of
+// cyrillic letters count as common but short ones don't so accented latin
+// text isn't taken for windows-1251
+func scoreText(text string) int {
+	runes := []rune(text)
+	score := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r < utf8.RuneSelf {
+			continue
+		}
+		if unicode.Is(unicode.Cyrillic, r) {
+			j := i
+			for j < len(runes) && unicode.Is(unicode.Cyrillic, runes[j]) {
+				j++
+			}
+			if n := j - i; n >= 3 {
+				score += 2 * n
+			} else {
+				score += n
+			}
+			i = j - 1
+			continue
+		}
+		switch {
+		case strings.ContainsRune(commonLetters, r):
+			score += 2
+		case unicode.IsLetter(r):
+			score++
+		default:
+			score--
+		}
+	}
+	return score
+}
+
+// Returns the name of the most likely encoding of data
+func DetectEncoding(data []byte) string {
+	if utf8.Valid(data) {
+		return "utf-8"
+	}
+
+	best, bestScore := "", 0
+	for _, e := range Encodings[1:] {
+		text, err := e.NewDecoder().Bytes(data)
+		if err != nil {
+			continue
+		}
+		if score := scoreText(string(text)); best == "" || score > bestScore {
+			best, bestScore = e.Name, score
+		}
+	}
+	return best
+}
+
+// Decodes data with enc like enc.NewDecoder().Bytes, mapping each byte offset
+// of the decoded text and its end to the offset of the encoded character it
+// was decoded from. Bytes decoded to nothing, like a byte order mark, go with
+// the character after them
+func decode(enc encoding.Encoding, data []byte) ([]byte, []int, error) {
+	dec := enc.NewDecoder()
+	out := make([]byte, 0, len(data))
+	offsets := make([]int, 0, len(data)+1)
+	buf := make([]byte, 64)
+	from := 0
+	for i := 0; i < len(data); {
+		// Feeds the decoder byte by byte until it takes a whole character
+		n := 0
+		for end := i + 1; n == 0 && end <= len(data); end++ {
+			nDst, nSrc, err := dec.Transform(buf, data[i:end], end == len(data))
+			if err != nil && err != transform.ErrShortSrc {
+				return nil, nil, err
+			}
+			for j := 0; j < nDst; j++ {
+				offsets = append(offsets, from)
+			}
+			out, n = append(out, buf[:nDst]...), nSrc
+			if nDst > 0 {
+				from = i + nSrc
+			}
+		}
+		if n == 0 {
+			break
+		}
+		i += n
+	}
+	return out, append(offsets, len(data)), nil
+}
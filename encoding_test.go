@@ -0,0 +1,132 @@
+package mt940
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	xunicode "golang.org/x/text/encoding/unicode"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	encode := func(name, s string) string {
+		out, err := LookupEncoding(name).NewEncoder().String(s)
+		if err != nil {
+			panic(err)
+		}
+		return out
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"ascii", ":86:Betaling sieraden", "utf-8"},
+		{"utf-8", ":86:Tranzakciós Illeték", "utf-8"},
+		{"german", encode("iso-8859-1", ":86:Überweisung Grüße an Jörg"), "iso-8859-1"},
+		{"hungarian", encode("iso-8859-2", ":86:Tranzakciós díj, kőműves, fűtés"), "iso-8859-2"},
+		{"polish", encode("windows-1250", ":86:Przelew środków, opłata źródło"), "windows-1250"},
+		{"russian", encode("windows-1251", ":86:Оплата по счету за услуги связи"), "windows-1251"},
+		{"dos hungarian", encode("ibm852", ":86:Csoportos átutalás jóváírása"), "ibm852"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectEncoding([]byte(tt.input)); got != tt.want {
+				t.Errorf("DetectEncoding() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectEncoding_Fixtures(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"self-provided/raiffeisen-cmi.sta", "ibm852"},
+		// Already UTF-8, the bytes lost before are U+FFFD in the file
+		{"sberbank/171011_01234945.sta", "utf-8"},
+		{"betterplace/with_binary_character.sta", "utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := DetectEncoding(must(os.ReadFile(tt.file))); got != tt.want {
+				t.Errorf("DetectEncoding() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransactions_Parse_Encoding(t *testing.T) {
+	input := ":20:REF\n:60F:C180417HUF1,00\n:61:180417CF2066637,00N527\n:86:" +
+		string([]byte("Csoportos \xa0tutal\xa0s j\xa2v\xa0\xa1r\xa0sa")) + "\n:62F:C180417HUF1,00\n"
+
+	tr := &Transactions{AutoDetectEncoding: true}
+	got, err := tr.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.DetectedEncoding != "ibm852" || string(tr.Raw) != input {
+		t.Errorf("DetectedEncoding = %v, Raw = %q", tr.DetectedEncoding, tr.Raw)
+	}
+	if got[0].TransactionDetails != "Csoportos átutalás jóváírása" {
+		t.Errorf("TransactionDetails = %q", got[0].TransactionDetails)
+	}
+
+	tr = &Transactions{Encoding: charmap.CodePage852}
+	got, err = tr.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.DetectedEncoding != "" || got[0].TransactionDetails != "Csoportos átutalás jóváírása" {
+		t.Errorf("TransactionDetails = %q", got[0].TransactionDetails)
+	}
+
+	raiffeisen := must(os.ReadFile("self-provided/raiffeisen-cmi.sta"))
+	tr = &Transactions{AutoDetectEncoding: true}
+	got, err = tr.Parse(bytes.NewReader(raiffeisen))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tr.Raw, raiffeisen) || len(got) != 1 || len(got[0].Entries) != 7 {
+		t.Fatalf("Transactions.Parse() = %v statements, Raw %v bytes", len(got), len(tr.Raw))
+	}
+	if e := got[0].Entries[0]; e.ExtraDetails != "Csoportos átutalás jóváírása" || !strings.Contains(e.TransactionDetails, "összevont utánvét") {
+		t.Errorf("entry 1 = %q, %q", e.ExtraDetails, e.TransactionDetails)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	text := ":20:REF\n:86:Csoportos átutalás jóváírása\n:62F:C180417HUF1,00\n"
+	tests := []struct {
+		name string
+		enc  encoding.Encoding
+	}{
+		{"ibm852", charmap.CodePage852},
+		{"utf-8", xunicode.UTF8},
+		{"utf-16", xunicode.UTF16(xunicode.LittleEndian, xunicode.UseBOM)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := must(tt.enc.NewEncoder().Bytes([]byte(text)))
+			got, offsets, err := decode(tt.enc, data)
+			if err != nil || string(got) != text {
+				t.Fatalf("decode() = %q, %v, want %q", got, err, text)
+			}
+			if len(offsets) != len(got)+1 || offsets[0] != 0 || offsets[len(got)] != len(data) {
+				t.Fatalf("decode() offsets %v", offsets)
+			}
+			i := strings.Index(text, ":62F:")
+			want := must(tt.enc.NewEncoder().Bytes([]byte(text[i:])))
+			if tt.name == "utf-16" {
+				want = want[2:]
+			}
+			if string(data[offsets[i]:]) != string(want) {
+				t.Errorf("decode() maps :62F: to %q, want %q", data[offsets[i]:], want)
+			}
+		})
+	}
+}
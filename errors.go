@@ -0,0 +1,159 @@
+package mt940
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Any error can be a ParseError, the ones returned by the parser are the
+// sentinels and types below, tell them apart with errors.Is and errors.As
+type ParseError interface {
+	error
+}
+
+type sentinelError struct {
+	msg string
+}
+
+func (e *sentinelError) Error() string {
+	return e.msg
+}
+
+func NewParseError(s string) ParseError {
+	return &sentinelError{s}
+}
+
+// Errors which aren't a ParseError already are wrapped in a SyntaxError
+func WrapParseError(e error) ParseError {
+	if e == nil {
+		return nil
+	}
+	switch e.(type) {
+	case *sentinelError, *SyntaxError, *UnknownTagError, *FieldError, *ValidationError, *IOError, *TagError, Errors:
+		return e
+	}
+	return &SyntaxError{Err: e}
+}
+
+var (
+	ErrFieldTooLong = NewParseError("field too long")
+	ErrValidation   = NewParseError("validation failed")
+	ErrIO           = NewParseError("reading input failed")
+)
+
+// The value of a tag doesn't match its format, Err is either one of the
+// sentinel errors or the underlying conversion error
+type SyntaxError struct {
+	Err error
+}
+
+func (se *SyntaxError) Error() string {
+	return se.Err.Error()
+}
+
+func (se *SyntaxError) Unwrap() error {
+	return se.Err
+}
+
+type UnknownTagError struct {
+	ID string
+}
+
+func (ue *UnknownTagError) Error() string {
+	return fmt.Sprintf("unknown tag :%v:", ue.ID)
+}
+
+func (ue *UnknownTagError) Unwrap() error {
+	return ErrNotExist
+}
+
+type FieldError struct {
+	Field  string
+	Value  string // Full raw value, never truncated
+	Length int
+	Max    int
+	Unit   string
+}
+
+func (fe *FieldError) Error() string {
+	return fmt.Sprintf(
+		"field %v is %v %v long, maximum is %v",
+		fe.Field, fe.Length, fe.Unit, fe.Max)
+}
+
+func (fe *FieldError) Unwrap() error {
+	return ErrFieldTooLong
+}
+
+type ValidationError struct {
+	Rule string
+	Msg  string
+}
+
+func (ve *ValidationError) Error() string {
+	return fmt.Sprintf("%v: %v", ve.Rule, ve.Msg)
+}
+
+func (ve *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+type IOError struct {
+	Err error
+}
+
+func (ie *IOError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrIO, ie.Err)
+}
+
+func (ie *IOError) Unwrap() error {
+	return ie.Err
+}
+
+func (ie *IOError) Is(target error) bool {
+	return target == ErrIO
+}
+
+// Aggregate of the errors collected while parsing leniently
+type Errors []error
+
+func (es Errors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return fmt.Sprintf("%d errors: %v", len(es), strings.Join(msgs, "; "))
+}
+
+func (es Errors) Unwrap() []error {
+	return es
+}
+
+// Is and As walk the errors themselves, Unwrap() []error is only understood
+// by errors.Is and errors.As from go 1.20 on
+func (es Errors) Is(target error) bool {
+	for _, e := range es {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (es Errors) As(target interface{}) bool {
+	for _, e := range es {
+		if errors.As(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns nil when there are no errors so the result can be returned as is
+func (es Errors) Err() ParseError {
+	if len(es) == 0 {
+		return nil
+	}
+	return es
+}
@@ -0,0 +1,101 @@
+package mt940
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("disk on fire")
+}
+
+func TestTransactions_Parse_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		target error
+	}{
+		{"unknown tag", ":20:REF\n:99:value", ErrNotExist},
+		{"syntax", ":20:REF\n:60F:X", ErrTagDidNotParse},
+		{"field length", ":20:" + strings.Repeat("1", 17), ErrFieldTooLong},
+		{"date", ":20:REF\n:60F:C171311HUF1,00", nil},
+		{"amount", ":20:REF\n:60F:C171011HUF1,,00", ErrMisformatedTag},
+		{"minor units", ":20:REF\n:60F:C171011BHD1,1255", ErrFieldTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&Transactions{}).Parse(strings.NewReader(tt.input))
+			var te *TagError
+			if !errors.As(err, &te) {
+				t.Fatalf("Transactions.Parse() error = %v, want *TagError", err)
+			}
+			if tt.target == nil {
+				var se *SyntaxError
+				if !errors.As(err, &se) {
+					t.Errorf("Transactions.Parse() error = %v, want *SyntaxError", err)
+				}
+			} else if !errors.Is(err, tt.target) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.target)
+			}
+		})
+	}
+
+	_, err := (&Transactions{}).Parse(failingReader{})
+	var ie *IOError
+	if !errors.As(err, &ie) || !errors.Is(err, ErrIO) || ie.Err.Error() != "disk on fire" {
+		t.Errorf("Transactions.Parse() error = %v, want *IOError", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	es := Errors{
+		&TagError{ParseError: &FieldError{Field: "transaction_reference"}},
+		&TagError{ParseError: &UnknownTagError{"99"}},
+	}
+	if !errors.Is(es, ErrFieldTooLong) || !errors.Is(es, ErrNotExist) || errors.Is(es, ErrIO) {
+		t.Errorf("errors.Is() on %v", es)
+	}
+	var ue *UnknownTagError
+	if !errors.As(es, &ue) || ue.ID != "99" {
+		t.Errorf("errors.As() on %v", es)
+	}
+	if Errors(nil).Err() != nil {
+		t.Errorf("Errors(nil).Err() != nil")
+	}
+}
+
+func TestWrapParseError(t *testing.T) {
+	_, err := strconv.Atoi("x")
+	var se *SyntaxError
+	if !errors.As(WrapParseError(err), &se) || !errors.Is(se, strconv.ErrSyntax) {
+		t.Errorf("WrapParseError() = %#v", WrapParseError(err))
+	}
+	if WrapParseError(ErrNotExist) != ErrNotExist {
+		t.Errorf("WrapParseError() wrapped a ParseError")
+	}
+	if WrapParseError(nil) != nil {
+		t.Errorf("WrapParseError(nil) != nil")
+	}
+	wrapped := fmt.Errorf("reading: %w", ErrNotExist)
+	if !errors.As(WrapParseError(wrapped), &se) || !errors.Is(se, ErrNotExist) {
+		t.Errorf("WrapParseError() = %#v", WrapParseError(wrapped))
+	}
+}
+
+// Errors from elsewhere are ParseErrors too, so callers can keep a single
+// variable for both
+func TestParseError_Assign(t *testing.T) {
+	var err ParseError
+	if _, err = Stitch(nil); err != nil {
+		t.Fatal(err)
+	}
+	err = errors.New("disk on fire")
+	if errors.Is(err, ErrIO) {
+		t.Errorf("errors.Is(%v, ErrIO)", err)
+	}
+}
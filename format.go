@@ -0,0 +1,402 @@
+package mt940
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidFormat  = NewParseError("invalid format notation")
+	ErrFormatMismatch = NewParseError("value does not match the format")
+)
+
+// Character sets of the SWIFT format notation
+var formatCharsets = map[byte]string{
+	'n': `0-9`,
+	'a': `A-Z`,
+	'c': `0-9A-Z`,
+	'h': `0-9A-F`,
+	'x': `0-9A-Za-z/\-?:().,'+ `,
+	'y': `0-9A-Z.,\-()/='+:?!"%&*<>; `,
+	'z': `0-9A-Za-z.,\-()/='+:?!"%&*<>;{@#_ `,
+	'e': ` `,
+	'd': `0-9,`,
+}
+
+var formatCharsetRegexps = func() map[byte]*regexp.Regexp {
+	res := map[byte]*regexp.Regexp{}
+	for c, class := range formatCharsets {
+		res[c] = regexp.MustCompile("^[" + class + "]*$")
+	}
+	return res
+}()
+
+// Tag format compiled from SWIFT notation, eg. 6!n[4!n]2a[1!a]15d. Lengths
+// are maximums unless marked exact with !, [] encloses optional parts, n*m
+// allows n lines of m characters and anything else is a literal. A newline
+// in the notation is a line break in the value
+type Format struct {
+	Spec   string
+	Regexp *regexp.Regexp
+	parts  []formatPart
+	fields []*formatField
+}
+
+// Either a literal, a field or an optional group
+type formatPart struct {
+	literal  string
+	field    *formatField
+	optional []formatPart
+}
+
+type formatField struct {
+	name    string
+	group   string // Name of the subexpression in Regexp
+	charset byte
+	length  int
+	lines   int // Number of lines for n*m fields, 1 otherwise
+	exact   bool
+	values  []string // Allowed codes, any value of the charset when empty
+}
+
+// Compiles spec with names naming its fields in order of appearance, fields
+// without a name are matched but not reported
+func CompileFormat(spec string, names ...string) (*Format, error) {
+	f := &Format{Spec: spec}
+	rest, parts, err := f.parseParts(spec, names)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("%w: unexpected ] in %q", ErrInvalidFormat, spec)
+	}
+	if len(f.fields) < len(names) {
+		return nil, fmt.Errorf("%w: %v names for %v fields in %q", ErrInvalidFormat, len(names), len(f.fields), spec)
+	}
+	f.parts = parts
+	if err := f.compile(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *Format) compile() error {
+	var re strings.Builder
+	re.WriteString("^")
+	writeFormatRegexp(&re, f.parts)
+	re.WriteString("$")
+	var err error
+	if f.Regexp, err = regexp.Compile(re.String()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+	}
+	return nil
+}
+
+func MustCompileFormat(spec string, names ...string) *Format {
+	f, err := CompileFormat(spec, names...)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Returns a copy of the format restricting field name to a list of codes, for
+// subfields whose codes the notation doesn't express, eg. the D, C, RD or RC
+// mark of :61:
+func (f *Format) WithValues(name string, values ...string) (*Format, error) {
+	c, err := CompileFormat(f.Spec, f.Fields()...)
+	if err != nil {
+		return nil, err
+	}
+	for i, field := range f.fields {
+		c.fields[i].values = field.values
+	}
+	for _, field := range c.fields {
+		if field.name == name && name != "" {
+			field.values = values
+			return c, c.compile()
+		}
+	}
+	return nil, fmt.Errorf("%w: no field %v in %q", ErrInvalidFormat, name, f.Spec)
+}
+
+func (f *Format) MustWithValues(name string, values ...string) *Format {
+	f, err := f.WithValues(name, values...)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Parses parts until the end of spec or a ], which is returned with the rest
+// of spec
+func (f *Format) parseParts(spec string, names []string) (string, []formatPart, error) {
+	var parts []formatPart
+	for spec != "" {
+		switch c := spec[0]; {
+		case c == '[':
+			rest, optional, err := f.parseParts(spec[1:], names)
+			if err != nil {
+				return "", nil, err
+			}
+			if rest == "" || rest[0] != ']' {
+				return "", nil, fmt.Errorf("%w: unclosed [ in %q", ErrInvalidFormat, f.Spec)
+			}
+			parts = append(parts, formatPart{optional: optional})
+			spec = rest[1:]
+		case c == ']':
+			return spec, parts, nil
+		case c >= '0' && c <= '9':
+			field, rest, err := parseFormatField(spec)
+			if err != nil {
+				return "", nil, fmt.Errorf("%w in %q", err, f.Spec)
+			}
+			if i := len(f.fields); i < len(names) {
+				field.name = names[i]
+			}
+			field.group = "f" + strconv.Itoa(len(f.fields))
+			f.fields = append(f.fields, field)
+			parts = append(parts, formatPart{field: field})
+			spec = rest
+		default:
+			if n := len(parts); n > 0 && parts[n-1].field == nil && parts[n-1].optional == nil {
+				parts[n-1].literal += string(c)
+			} else {
+				parts = append(parts, formatPart{literal: string(c)})
+			}
+			spec = spec[1:]
+		}
+	}
+	return "", parts, nil
+}
+
+// Parses a field like 16x, 3!a or 6*65x from the start of spec
+func parseFormatField(spec string) (*formatField, string, error) {
+	number := func() int {
+		i := 0
+		for i < len(spec) && spec[i] >= '0' && spec[i] <= '9' {
+			i++
+		}
+		n, _ := strconv.Atoi(spec[:i])
+		spec = spec[i:]
+		return n
+	}
+
+	field := &formatField{length: number(), lines: 1}
+	if spec != "" && spec[0] == '*' {
+		spec = spec[1:]
+		field.lines, field.length = field.length, number()
+	}
+	if spec != "" && spec[0] == '!' {
+		spec = spec[1:]
+		field.exact = true
+	}
+	if spec == "" {
+		return nil, "", fmt.Errorf("%w: missing character set", ErrInvalidFormat)
+	}
+	if _, ok := formatCharsets[spec[0]]; !ok {
+		return nil, "", fmt.Errorf("%w: unknown character set %q", ErrInvalidFormat, spec[0])
+	}
+	field.charset = spec[0]
+	switch {
+	case field.length == 0 || field.lines == 0:
+		return nil, "", fmt.Errorf("%w: zero length", ErrInvalidFormat)
+	case field.exact && field.lines > 1:
+		return nil, "", fmt.Errorf("%w: exact multi-line field", ErrInvalidFormat)
+	case field.charset == 'd' && (field.exact || field.lines > 1):
+		return nil, "", fmt.Errorf("%w: decimals are single line with a maximum length", ErrInvalidFormat)
+	}
+	return field, spec[1:], nil
+}
+
+// Separator of the optional parts that subfields like the customer and bank
+// reference of :61: can't contain
+const formatSubfieldSeparator = "//"
+
+func writeFormatRegexp(re *strings.Builder, parts []formatPart) {
+	for i, p := range parts {
+		switch {
+		case p.field != nil:
+			pattern := p.field.pattern()
+			if i+1 < len(parts) && startsWithLiteral(parts[i+1].optional, formatSubfieldSeparator) {
+				pattern = p.field.patternBeforeSeparator()
+			}
+			re.WriteString("(?P<" + p.field.group + ">" + pattern + ")")
+		case p.optional != nil:
+			re.WriteString("(?:")
+			writeFormatRegexp(re, p.optional)
+			re.WriteString(")?")
+		default:
+			re.WriteString(strings.ReplaceAll(regexp.QuoteMeta(p.literal), "\n", `\r?\n`))
+		}
+	}
+}
+
+func (ff *formatField) pattern() string {
+	class := "[" + formatCharsets[ff.charset] + "]"
+	switch {
+	case len(ff.values) > 0:
+		quoted := make([]string, len(ff.values))
+		for i, v := range ff.values {
+			quoted[i] = regexp.QuoteMeta(v)
+		}
+		return "(?:" + strings.Join(quoted, "|") + ")"
+	case ff.charset == 'd':
+		return `[0-9]+(?:,[0-9]*)?`
+	case ff.exact:
+		return fmt.Sprintf("%v{%v}", class, ff.length)
+	case ff.lines > 1:
+		return fmt.Sprintf(`%v{1,%v}(?:\r?\n%v{1,%v}){0,%v}`, class, ff.length, class, ff.length, ff.lines-1)
+	}
+	return fmt.Sprintf("%v{1,%v}", class, ff.length)
+}
+
+// Matches the field up to the first //, single slashes are kept. The length
+// isn't bounded so check reports overlong values
+func (ff *formatField) patternBeforeSeparator() string {
+	if len(ff.values) > 0 || ff.exact || ff.lines > 1 || !strings.Contains(formatCharsets[ff.charset], "/") {
+		return ff.pattern()
+	}
+	class := "[" + strings.Replace(formatCharsets[ff.charset], "/", "", 1) + "]"
+	return fmt.Sprintf("(?:(?:%v|/%v)+/?|/)", class, class)
+}
+
+func startsWithLiteral(parts []formatPart, literal string) bool {
+	return len(parts) > 0 && strings.HasPrefix(parts[0].literal, literal)
+}
+
+// Checks a value against the field, lines are separated by LF
+func (ff *formatField) check(value string) error {
+	lines := strings.Split(value, "\n")
+	if len(lines) > ff.lines {
+		return &FieldError{ff.name, value, len(lines), ff.lines, "lines"}
+	}
+	for _, line := range lines {
+		n := utf8.RuneCountInString(line)
+		switch {
+		case n > ff.length:
+			return &FieldError{ff.name, value, n, ff.length, "characters"}
+		case ff.exact && n != ff.length, n == 0:
+			return &SyntaxError{fmt.Errorf("%w: field %v is %v characters long, want %v", ErrFormatMismatch, ff.name, n, ff.length)}
+		case ff.charset == 'd' && strings.Count(line, ",") > 1:
+			return &SyntaxError{fmt.Errorf("%w: field %v has more than one decimal comma", ErrFormatMismatch, ff.name)}
+		}
+		if !formatCharsetRegexps[ff.charset].MatchString(line) {
+			return &SyntaxError{fmt.Errorf("%w: field %v contains characters outside of %c", ErrFormatMismatch, ff.name, ff.charset)}
+		}
+	}
+	if len(ff.values) > 0 && !containsString(ff.values, value) {
+		return &SyntaxError{fmt.Errorf("%w: field %v is %q, want one of %v", ErrFormatMismatch, ff.name, value, ff.values)}
+	}
+	return nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Names of the fields in order of appearance
+func (f *Format) Fields() []string {
+	names := make([]string, len(f.fields))
+	for i, field := range f.fields {
+		names[i] = field.name
+	}
+	return names
+}
+
+// Parses a tag value, results hold the named fields with empty strings for
+// absent optional ones and LF line endings
+func (f *Format) Parse(value string) (TagResults, error) {
+	match := f.Regexp.FindStringSubmatch(value)
+	if match == nil {
+		return nil, &SyntaxError{fmt.Errorf("%w: %q is not %v", ErrFormatMismatch, value, f.Spec)}
+	}
+	result := TagResults{}
+	for _, field := range f.fields {
+		v := strings.ReplaceAll(match[f.Regexp.SubexpIndex(field.group)], "\r\n", "\n")
+		if v != "" {
+			// Decimal lengths include the comma so they can't be expressed
+			// in the regexp
+			if err := field.check(v); err != nil {
+				return nil, err
+			}
+		}
+		if field.name != "" {
+			result[field.name] = v
+		}
+	}
+	return result, nil
+}
+
+// Writes the named fields of r in the format, optional parts are left out
+// when none of their fields have a value
+func (f *Format) Write(r TagResults) (string, error) {
+	var b strings.Builder
+	err := writeFormatParts(&b, f.parts, r, func(err error) error { return err })
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Writes like Write but keeps values that don't fit their fields as they are,
+// returning their errors. Missing mandatory fields still fail
+func (f *Format) WriteLenient(r TagResults) (string, Errors, error) {
+	var b strings.Builder
+	var errs Errors
+	err := writeFormatParts(&b, f.parts, r, func(err error) error {
+		errs = append(errs, err)
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return b.String(), errs, nil
+}
+
+// Values that don't fit their field are passed to mismatch, which returns
+// the error to fail with or nil to write them anyway
+func writeFormatParts(b *strings.Builder, parts []formatPart, r TagResults, mismatch func(error) error) error {
+	for _, p := range parts {
+		switch {
+		case p.field != nil:
+			value := r[p.field.name]
+			if value == "" {
+				return &SyntaxError{fmt.Errorf("%w: field %v is mandatory", ErrFormatMismatch, p.field.name)}
+			}
+			if err := p.field.check(value); err != nil {
+				if err := mismatch(err); err != nil {
+					return err
+				}
+			}
+			b.WriteString(value)
+		case p.optional != nil:
+			if !hasFormatValue(p.optional, r) {
+				continue
+			}
+			if err := writeFormatParts(b, p.optional, r, mismatch); err != nil {
+				return err
+			}
+		default:
+			b.WriteString(p.literal)
+		}
+	}
+	return nil
+}
+
+func hasFormatValue(parts []formatPart, r TagResults) bool {
+	for _, p := range parts {
+		if p.field != nil && r[p.field.name] != "" || p.optional != nil && hasFormatValue(p.optional, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package mt940
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCompileFormat(t *testing.T) {
+	tests := []struct {
+		spec    string
+		names   []string
+		wantErr bool
+	}{
+		{"16x", []string{"reference"}, false},
+		{"6!n[4!n]2a[1!a]15d1!a3!c16x[//16x][34x]", nil, false},
+		{"6*65x", []string{"details"}, false},
+		{"5n[/5n]", []string{"number", "sequence"}, false},
+		{"16q", nil, true},
+		{"[16x", nil, true},
+		{"16x]", nil, true},
+		{"0x", nil, true},
+		{"3*5!x", nil, true},
+		{"15!d", nil, true},
+		{"16", nil, true},
+		{"16x", []string{"a", "b"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := CompileFormat(tt.spec, tt.names...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CompileFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidFormat) {
+				t.Errorf("CompileFormat() error = %v, want %v", err, ErrInvalidFormat)
+			}
+		})
+	}
+
+	if _, err := MustCompileFormat("2a", "status").WithValues("mark", "D", "C"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Format.WithValues() of a missing field error = %v, want %v", err, ErrInvalidFormat)
+	}
+}
+
+func TestFormat_Parse(t *testing.T) {
+	statementLine := MustCompileFormat("6!n[4!n]2a[1!a]15d1!a3!c16x[//16x][\n34x]",
+		"value_date", "entry_date", "status", "funds_code", "amount",
+		"id_class", "id_code", "customer_reference", "bank_reference", "extra_details")
+	marked := statementLine.MustWithValues("status", "D", "C", "RD", "RC")
+	tests := []struct {
+		name    string
+		format  *Format
+		value   string
+		want    TagResults
+		wantErr error
+	}{
+		{
+			"statement line", statementLine, "1112021202D43,6NTRFNONREF//8327000090031789\r\nCard transaction",
+			TagResults{
+				"value_date": "111202", "entry_date": "1202", "status": "D", "funds_code": "", "amount": "43,6",
+				"id_class": "N", "id_code": "TRF", "customer_reference": "NONREF",
+				"bank_reference": "8327000090031789", "extra_details": "Card transaction",
+			},
+			nil,
+		},
+		{
+			"statement line without options", statementLine, "230301RCR366336,2NCHKREF",
+			TagResults{
+				"value_date": "230301", "entry_date": "", "status": "RC", "funds_code": "R", "amount": "366336,2",
+				"id_class": "N", "id_code": "CHK", "customer_reference": "REF",
+				"bank_reference": "", "extra_details": "",
+			},
+			nil,
+		},
+		{
+			"mark and funds code", marked, "1701190119CN0,01NTRFNONREF",
+			TagResults{
+				"value_date": "170119", "entry_date": "0119", "status": "C", "funds_code": "N", "amount": "0,01",
+				"id_class": "N", "id_code": "TRF", "customer_reference": "NONREF",
+				"bank_reference": "", "extra_details": "",
+			},
+			nil,
+		},
+		{
+			"reversal mark and funds code", marked, "230301RCR366336,2NCHKREF",
+			TagResults{
+				"value_date": "230301", "entry_date": "", "status": "RC", "funds_code": "R", "amount": "366336,2",
+				"id_class": "N", "id_code": "CHK", "customer_reference": "REF",
+				"bank_reference": "", "extra_details": "",
+			},
+			nil,
+		},
+		{
+			"references", marked, "230301C1,00NTRFREF/1//BANK/2",
+			TagResults{
+				"value_date": "230301", "entry_date": "", "status": "C", "funds_code": "", "amount": "1,00",
+				"id_class": "N", "id_code": "TRF", "customer_reference": "REF/1",
+				"bank_reference": "BANK/2", "extra_details": "",
+			},
+			nil,
+		},
+		{"customer reference too long", marked, "230301C1,00NTRFNL47INGB9999999999//BANK", nil, ErrFieldTooLong},
+		{"unknown mark", marked, "230301XD1,00NTRFREF", nil, ErrFormatMismatch},
+		{
+			"any mark without values", statementLine, "230301XD1,00NTRFREF",
+			TagResults{
+				"value_date": "230301", "entry_date": "", "status": "XD", "funds_code": "", "amount": "1,00",
+				"id_class": "N", "id_code": "TRF", "customer_reference": "REF",
+				"bank_reference": "", "extra_details": "",
+			},
+			nil,
+		},
+		{"exact length", statementLine, "11120D43,6NTRFNONREF", nil, ErrFormatMismatch},
+		{"decimal without comma", MustCompileFormat("3!a5d", "currency", "amount"), "EUR960", TagResults{"currency": "EUR", "amount": "960"}, nil},
+		{"decimal with two commas", MustCompileFormat("3!a5d", "currency", "amount"), "EUR1,2,3", nil, ErrFormatMismatch},
+		{"decimal too long", MustCompileFormat("3!a5d", "currency", "amount"), "EUR1234,5", nil, ErrFieldTooLong},
+		{"decimal", MustCompileFormat("3!a5d", "currency", "amount"), "EUR123,5", TagResults{"currency": "EUR", "amount": "123,5"}, nil},
+		{
+			"lines", MustCompileFormat("2*5x", "details"), "ABCDE\r\nFGH",
+			TagResults{"details": "ABCDE\nFGH"}, nil,
+		},
+		{"too many lines", MustCompileFormat("2*5x", "details"), "A\nB\nC", nil, ErrFormatMismatch},
+		{"charset", MustCompileFormat("3!a", "currency"), "eur", nil, ErrFormatMismatch},
+		{"unnamed", MustCompileFormat("5n[/5n]", "number"), "355/1", TagResults{"number": "355"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.format.Parse(tt.value)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("Format.Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Format.Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat_Write(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		names   []string
+		values  TagResults
+		want    string
+		wantErr error
+	}{
+		{"optional", "5n[/5n]", []string{"number", "sequence"}, TagResults{"number": "355"}, "355", nil},
+		{"optional set", "5n[/5n]", []string{"number", "sequence"}, TagResults{"number": "355", "sequence": "1"}, "355/1", nil},
+		{"mandatory", "5n[/5n]", []string{"number", "sequence"}, TagResults{"sequence": "1"}, "", ErrFormatMismatch},
+		{"too long", "16x", []string{"reference"}, TagResults{"reference": "12345678901234567"}, "", ErrFieldTooLong},
+		{"exact", "3!a", []string{"currency"}, TagResults{"currency": "EU"}, "", ErrFormatMismatch},
+		{"lines", "6*65x", []string{"details"}, TagResults{"details": "A\nB"}, "A\nB", nil},
+		{
+			"balance", "1!a6!n3!a15d", []string{"status", "date", "currency", "amount"},
+			TagResults{"status": "C", "date": "230228", "currency": "DKK", "amount": "12724930,14"},
+			"C230228DKK12724930,14", nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := MustCompileFormat(tt.spec, tt.names...)
+			got, err := f.Write(tt.values)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("Format.Write() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Format.Write() = %q, want %q", got, tt.want)
+			}
+			if err != nil {
+				return
+			}
+			parsed, err := f.Parse(got)
+			if err != nil {
+				t.Fatalf("Format.Parse() of written value error = %v", err)
+			}
+			for _, name := range tt.names {
+				if parsed[name] != tt.values[name] {
+					t.Errorf("Format.Parse() %v = %q, want %q", name, parsed[name], tt.values[name])
+				}
+			}
+		})
+	}
+}
+
+func TestFormat_WriteLenient(t *testing.T) {
+	f := MustCompileFormat("2*5x", "details")
+	got, errs, err := f.WriteLenient(TagResults{"details": "A;B\nC\nD"})
+	if err != nil || got != "A;B\nC\nD" {
+		t.Fatalf("Format.WriteLenient() = %q, %v, want the value as it is", got, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs, ErrFieldTooLong) {
+		t.Errorf("Format.WriteLenient() errors = %v, want the 3 lines", errs)
+	}
+	if _, _, err := f.WriteLenient(TagResults{}); !errors.Is(err, ErrFormatMismatch) {
+		t.Errorf("Format.WriteLenient() error = %v, want the missing field", err)
+	}
+}
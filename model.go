@@ -11,24 +11,14 @@ import (
 	"time"
 
 	"golang.org/x/text/currency"
+	"golang.org/x/text/encoding"
 )
 
-type ParseError interface {
-	error
-}
-
-func NewParseError(s string) ParseError {
-	return errors.New(s)
-}
-
-func WrapParseError(e error) ParseError {
-	return e
-}
-
 var (
 	ErrTagDoesNotApply   = NewParseError("tag doesn't apply to this struct")
 	ErrNoTagsFound       = NewParseError("no tags found")
 	ErrTagResultsMissing = NewParseError("missing expected tag results fields")
+	ErrIgnoreTag         = NewParseError("ignore tag")
 )
 
 type TransactionDate struct {
@@ -36,7 +26,7 @@ type TransactionDate struct {
 }
 
 type Amount struct {
-	int64 // Hundredths of value (ie. cents)
+	int64 // Thousandths of value, which hold the minor units of any currency
 }
 
 type Balance struct {
@@ -44,6 +34,10 @@ type Balance struct {
 	Status    string
 	Amount
 	Currency currency.Unit
+
+	code     string   // Currency code as sent, even if it isn't ISO 4217
+	decimals int      // Digits following the decimal comma of the amount
+	pos      Position // Source of a repeated balance, Positions holds the others
 }
 
 type StatementLine struct {
@@ -56,47 +50,181 @@ type StatementLine struct {
 	BankReference     string
 	ExtraDetails      string
 	Amount
+
+	decimals int // Digits following the decimal comma of the amount
 }
 
-type Transaction struct {
+// Non-swift subfields keyed by their numeric code, the values of a repeated
+// code keep their order of appearance but the codes themselves are unordered
+type NonSwift map[string][]string
+
+// Maps the numeric non-swift codes of a bank to field names
+type NonSwiftProfile map[string]string
+
+type Entry struct {
 	StatementLine
+	TransactionDetails string
+	NonSwift           NonSwift
+	Positions          map[string]Position // Source of each tag by id
+}
+
+type Transaction struct {
+	StatementLine              // Most recent :61:, see Entries for all of them
 	TransactionReferenceNumber string
+	RelatedReference           string // :21:, eg. the :20: of the MT920 requesting the statement
+	AccountIdentification      string // :25:
+	StatementNumber            string // :28C:
+	StatementSeqNumber         string // :28C:, the page of a statement split into several
 	FinalOpeningBalance        Balance
 	AvailableBalance           Balance
 	FinalClosingBalance        Balance
+	IntermediateOpeningBalance Balance   // :60M: of the pages following the first
+	IntermediateClosingBalance Balance   // :62M: of the pages preceding the last
+	ForwardAvailableBalance    []Balance // :65:, one for each of the following days
 	TransactionDetails         string
+	HeaderInformation          string // :86: preceding the first :61:
+	Information                string // :86: following the closing balance
+	NonSwift                   NonSwift
+	Entries                    []Entry
+	FloorLimit                 *FloorLimit         // :34F:, nil when absent
+	DateTimeIndication         *time.Time          // :13D:, when the interim report was created
+	DebitEntries               *EntrySummary       // :90D:, nil when absent
+	CreditEntries              *EntrySummary       // :90C:, nil when absent
+	Positions                  map[string]Position // Source of each tag by id
+	Extra                      []ExtraTag          // Registered tags without a field
+	Unknown                    []ExtraTag          // Tags collected by OnUnknownTag, without results
+
+	closed bool
+	pos    Position // Position of the tag being added
 }
 
 type Transactions struct {
-	transactions          []Transaction
+	transactions []Transaction
+
+	// Most recent :25: and :28C:, the statements keep their own
 	AccountIdentification string
 	StatementNumber       string
 	StatementSeqNumber    string
+
+	// Keep values exceeding the SWIFT field lengths instead of failing, the
+	// violations are reported in Diagnostics
+	Lenient     bool
+	Diagnostics Errors
+	FileName    string // Used in positions
+
+	// Skip statements containing errors instead of failing, parsing continues
+	// at the next :20: or message separator
+	Recover bool
+	Skipped []SkippedRange
+
+	// The input is transcoded from Encoding to UTF-8 before tokenizing, with
+	// AutoDetectEncoding the encoding is guessed when Encoding is nil. Raw
+	// keeps the original bytes, positions refer to the transcoded input
+	Encoding           encoding.Encoding
+	AutoDetectEncoding bool
+	DetectedEncoding   string
+	Raw                []byte
+
+	// Replace control characters other than CR and LF and invalid UTF-8 with
+	// SanitizeReplacement, or strip them when it's 0. Input where more than
+	// MaxInvalidRatio of the bytes are invalid is rejected
+	Sanitize            bool
+	SanitizeReplacement rune
+	MaxInvalidRatio     float64
+	Replacements        []Replacement
+
+	// Tags known to the parser, DefaultRegistry when nil
+	Registry *Registry
+
+	// Check the order and cardinality of the tags of each statement against
+	// the message type, violations are reported like field length ones
+	MessageType MessageType
+
+	// Check the SWIFT network validated rules, the :90D:/:90C: sums, the
+	// :34F: floor limit and the currency suffix of the :25: account on each
+	// statement, violations are reported like field length ones
+	NetworkRules bool
+
+	// Called for tags that aren't registered or that the model doesn't
+	// support instead of failing. Returning nil collects the tag in the
+	// Unknown tags of the statement, ErrIgnoreTag drops it and any other
+	// error fails like a tag that didn't parse
+	OnUnknownTag func(id, raw string, pos Position) error
+
+	// Lossless tree of the input, its nodes hold the original bytes of Raw
+	// while their positions refer to the transcoded input like the others.
+	// The tag nodes link the fields of the tags that parsed to their source
+	CST *CST
+}
+
+type SkippedRange struct {
+	Pos Position // Offset and End span the skipped input
+	Err *TagError
 }
 
 type TagParser interface {
 	AddTag(t *Tag, r TagResults) *TagError
 }
 
+func NewAmount(hundredths int64) Amount {
+	return Amount{hundredths * 10}
+}
+
+// Value in hundredths, thousandths of currencies with three minor units are
+// truncated
+func (amt Amount) Hundredths() int64 {
+	return amt.int64 / 10
+}
+
+// SWIFT notation with a decimal comma, eg. 43,60. It isn't String so the
+// structs embedding Amount don't print as their amount
+func (amt Amount) Decimal() string {
+	return amt.format(2)
+}
+
+// Formats the amount with the minor units of a currency, decimals that don't
+// fit are kept
+func (amt Amount) format(decimals int) string {
+	fraction := fmt.Sprintf("%03d", amt.int64%1000)
+	for len(fraction) > decimals && fraction[len(fraction)-1] == '0' {
+		fraction = fraction[:len(fraction)-1]
+	}
+	return strconv.FormatInt(amt.int64/1000, 10) + "," + fraction
+}
+
+func countDecimals(amount string) int {
+	if i := strings.IndexByte(amount, ','); i >= 0 {
+		return len(amount) - i - 1
+	}
+	return 0
+}
+
+// Decimals without the trailing zeros
+func significantDecimals(amount string) int {
+	if i := strings.IndexByte(amount, ','); i >= 0 {
+		return len(strings.TrimRight(amount[i+1:], "0"))
+	}
+	return 0
+}
+
+var amountRegexp = regexp.MustCompile(`^([0-9]+)(?:,([0-9]*))?$`)
+
+// Decimals an Amount keeps
+const amountDecimals = 3
+
+// Parses a SWIFT amount with a decimal comma, decimals past the third are
+// dropped. The field limits of the tags report them
 func (amt *Amount) Parse(s string) error {
-	re := regexp.MustCompile(`([0-9]+)(?:,([0-9]{2}))?`)
-	groups := re.FindStringSubmatch(s)
-
-	var decimal string
-	switch len(groups) {
-	case 3:
-		decimal = groups[2]
-	case 2:
-		decimal = "00"
-	default:
+	groups := amountRegexp.FindStringSubmatch(s)
+	if groups == nil {
 		return ErrMisformatedTag
 	}
 
-	a, err := strconv.Atoi(groups[1] + decimal)
+	a, err := strconv.ParseInt(groups[1]+(groups[2] + "000")[:amountDecimals], 10, 64)
 	if err != nil {
 		return err
 	}
-	amt.int64 = int64(a)
+	amt.int64 = a
 	return nil
 }
 
@@ -116,30 +244,79 @@ func (td *TransactionDate) Parse(year, month, day string) error {
 
 func (b *Balance) AddTag(t *Tag, r TagResults) *TagError {
 	if err := b.Timestamp.Parse(r["year"], r["month"], r["day"]); err != nil {
-		return &TagError{err, t, ""}
+		return &TagError{ParseError: WrapParseError(err), Tag: t}
 	}
 
 	b.Status = r["status"]
-	b.Amount.Parse(r["amount"])
+	if err := b.Amount.Parse(r["amount"]); err != nil {
+		return &TagError{ParseError: WrapParseError(err), Tag: t}
+	}
+	b.decimals = countDecimals(r["amount"])
+	b.code = r["currency"]
+	if unit, err := currency.ParseISO(b.code); err == nil {
+		b.Currency = unit
+	}
 
 	return nil
 }
 
+// Entries below the floor limits of an interim report aren't reported
+type FloorLimit struct {
+	Debit    Amount
+	Credit   Amount
+	Currency currency.Unit
+}
+
+// A :34F: without a debit/credit mark applies to both
+func (fl *FloorLimit) AddTag(t *Tag, r TagResults) *TagError {
+	var amt Amount
+	if err := amt.Parse(r["amount"]); err != nil {
+		return &TagError{ParseError: WrapParseError(err), Tag: t}
+	}
+	if unit, err := currency.ParseISO(r["currency"]); err == nil {
+		fl.Currency = unit
+	}
+	switch r["status"] {
+	case "D":
+		fl.Debit = amt
+	case "C":
+		fl.Credit = amt
+	default:
+		fl.Debit, fl.Credit = amt, amt
+	}
+	return nil
+}
+
+func (s *EntrySummary) AddTag(t *Tag, r TagResults) *TagError {
+	count, err := strconv.Atoi(r["number"])
+	if err != nil {
+		return &TagError{ParseError: WrapParseError(err), Tag: t}
+	}
+	s.Count = count
+	if unit, err := currency.ParseISO(r["currency"]); err == nil {
+		s.Currency = unit
+	}
+	if err := s.Amount.Parse(r["amount"]); err != nil {
+		return &TagError{ParseError: WrapParseError(err), Tag: t}
+	}
+	return nil
+}
+
 func (sl *StatementLine) AddTag(t *Tag, r TagResults) *TagError {
 	if err := sl.Timestamp.Parse(r["year"], r["month"], r["day"]); err != nil {
-		return &TagError{err, t, ""}
+		return &TagError{ParseError: WrapParseError(err), Tag: t}
 	}
-	if err := sl.EntryTime.Parse(r["year"], r["entry_month"], r["entry_day"]); err != nil {
-		return &TagError{err, t, ""}
+	sl.EntryTime = TransactionDate{}
+	if r["entry_month"] != "" {
+		if err := sl.EntryTime.Parse(r["year"], r["entry_month"], r["entry_day"]); err != nil {
+			return &TagError{ParseError: WrapParseError(err), Tag: t}
+		}
 	}
 	sl.Status = r["status"]
 	sl.FundsCode = r["funds_code"]
-	_, err := fmt.Sscanf(
-		strings.Replace(r["amount"], ",", ".", -1),
-		"%f", &sl.Amount)
-
-	if err != nil {
-		return &TagError{err, t, ""}
+	sl.decimals = countDecimals(r["amount"])
+	if err := sl.Amount.Parse(r["amount"]); err != nil {
+		return &TagError{ParseError: WrapParseError(err), Tag: t}
 	}
 
 	sl.TransactionTypeID = r["id"]
@@ -158,84 +335,428 @@ func (ts *Transactions) AddTag(t *Tag, r TagResults) *TagError {
 		ts.StatementNumber = r["statement_number"]
 		ts.StatementSeqNumber = r["sequence_number"]
 	default:
-		return &TagError{ErrTagDoesNotApply, t, ""}
+		return &TagError{ParseError: ErrTagDoesNotApply, Tag: t}
 	}
 	return nil
 }
 
+// Returns the statements whose :21: refers to reference, NONREF never
+// matches since it marks statements that weren't requested
+func Related(statements []Transaction, reference string) []*Transaction {
+	reference = strings.TrimSpace(reference)
+	if reference == "" || reference == "NONREF" {
+		return nil
+	}
+	var related []*Transaction
+	for i := range statements {
+		if strings.TrimSpace(statements[i].RelatedReference) == reference {
+			related = append(related, &statements[i])
+		}
+	}
+	return related
+}
+
+// Statements of one account in order of appearance
+type AccountStatements struct {
+	Account    string // :25:, empty for statements without one
+	Statements []Transaction
+}
+
+// Groups statements by their :25: account, in order of each account's first
+// statement. Files of cash-management systems often hold many accounts
+func ByAccount(statements []Transaction) []AccountStatements {
+	var accounts []AccountStatements
+	index := make(map[string]int)
+	for _, tr := range statements {
+		i, ok := index[tr.AccountIdentification]
+		if !ok {
+			i = len(accounts)
+			index[tr.AccountIdentification] = i
+			accounts = append(accounts, AccountStatements{Account: tr.AccountIdentification})
+		}
+		accounts[i].Statements = append(accounts[i].Statements, tr)
+	}
+	return accounts
+}
+
+func (ns NonSwift) add(other NonSwift) NonSwift {
+	if ns == nil {
+		return other
+	}
+	for id, values := range other {
+		ns[id] = append(ns[id], values...)
+	}
+	return ns
+}
+
+func (ns NonSwift) Named(p NonSwiftProfile) map[string][]string {
+	named := make(map[string][]string, len(ns))
+	for id, values := range ns {
+		name, ok := p[id]
+		if !ok {
+			name = id
+		}
+		named[name] = append(named[name], values...)
+	}
+	return named
+}
+
+func (tr *Transaction) currentEntry() *Entry {
+	if len(tr.Entries) == 0 {
+		return nil
+	}
+	return &tr.Entries[len(tr.Entries)-1]
+}
+
 func (tr *Transaction) AddTag(t *Tag, r TagResults) *TagError {
+	positions := &tr.Positions
 	switch t.id {
 	case "20":
 		tr.TransactionReferenceNumber = r["transaction_reference"]
+	case "21":
+		tr.RelatedReference = r["related_reference"]
+	case "25":
+		tr.AccountIdentification = r["account_identification"]
+	case "28C":
+		tr.StatementNumber = r["statement_number"]
+		tr.StatementSeqNumber = r["sequence_number"]
 	case "60F":
-		return tr.FinalOpeningBalance.AddTag(t, r)
+		if err := tr.FinalOpeningBalance.AddTag(t, r); err != nil {
+			return err
+		}
+	case "60M":
+		if err := tr.IntermediateOpeningBalance.AddTag(t, r); err != nil {
+			return err
+		}
 	case "61":
-		tr.StatementLine.AddTag(t, r)
+		if err := tr.StatementLine.AddTag(t, r); err != nil {
+			return err
+		}
+		tr.Entries = append(tr.Entries, Entry{StatementLine: tr.StatementLine})
+		positions = &tr.currentEntry().Positions
 	case "62F":
-		return tr.FinalClosingBalance.AddTag(t, r)
+		if err := tr.FinalClosingBalance.AddTag(t, r); err != nil {
+			return err
+		}
+		tr.closed = true
+	case "62M":
+		if err := tr.IntermediateClosingBalance.AddTag(t, r); err != nil {
+			return err
+		}
+		tr.closed = true
 	case "64":
-		return tr.AvailableBalance.AddTag(t, r)
+		if err := tr.AvailableBalance.AddTag(t, r); err != nil {
+			return err
+		}
+		tr.closed = true
+	case "65":
+		b := Balance{pos: tr.pos}
+		if err := b.AddTag(t, r); err != nil {
+			return err
+		}
+		tr.ForwardAvailableBalance = append(tr.ForwardAvailableBalance, b)
+		tr.closed = true
+	case "34F":
+		if tr.FloorLimit == nil {
+			tr.FloorLimit = &FloorLimit{}
+		}
+		if err := tr.FloorLimit.AddTag(t, r); err != nil {
+			return err
+		}
+	case "13D":
+		var date TransactionDate
+		if err := date.Parse(r["year"], r["month"], r["day"]); err != nil {
+			return &TagError{ParseError: WrapParseError(err), Tag: t}
+		}
+		created, err := time.Parse("2006-01-02 1504 -0700",
+			date.Format("2006-01-02")+" "+r["hour"]+r["minute"]+" "+r["sign"]+r["offset"])
+		if err != nil {
+			return &TagError{ParseError: WrapParseError(err), Tag: t}
+		}
+		tr.DateTimeIndication = &created
+	case "90D", "90C":
+		summary := &EntrySummary{}
+		if err := summary.AddTag(t, r); err != nil {
+			return err
+		}
+		if t.status == "D" {
+			tr.DebitEntries = summary
+		} else {
+			tr.CreditEntries = summary
+		}
+		tr.closed = true
 	case "86":
-		tr.TransactionDetails = r["transaction_details"]
+		details := r["transaction_details"]
+		e := tr.currentEntry()
+		switch {
+		case tr.closed:
+			tr.Information = joinLines(tr.Information, details)
+		case e != nil:
+			tr.TransactionDetails = details
+			e.TransactionDetails = details
+			positions = &e.Positions
+		default:
+			tr.HeaderInformation = joinLines(tr.HeaderInformation, details)
+		}
+	case "NS":
+		ns := t.ParseSubfields(r["non_swift"])
+		if e := tr.currentEntry(); e != nil && !tr.closed {
+			e.NonSwift = e.NonSwift.add(ns)
+			positions = &e.Positions
+		} else {
+			tr.NonSwift = tr.NonSwift.add(ns)
+		}
 	default:
-		return &TagError{ErrTagDoesNotApply, t, ""}
+		return &TagError{ParseError: ErrTagDoesNotApply, Tag: t}
+	}
+	if tr.pos.IsValid() {
+		if *positions == nil {
+			*positions = make(map[string]Position)
+		}
+		(*positions)[t.id] = tr.pos
 	}
 	return nil
 }
 
+func joinLines(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "\n" + b
+}
+
+// Index of the token to continue with after a failure in token i, which is
+// the next :20: or the first tag after a message separator
+func resync(tokens []token, i int) int {
+	for j := i + 1; j < len(tokens); j++ {
+		if tokens[j].id == "20" || tokens[j].separated {
+			return j
+		}
+	}
+	return len(tokens)
+}
+
+func (t *Transactions) addTag(tr *Transaction, tag *Tag, result TagResults) *TagError {
+	parsers := []TagParser{
+		tr, t,
+	}
+
+	// Every parser the tag applies to gets it, eg. :25: sets the account of
+	// the statement and of the file
+	var err *TagError
+	applied := false
+	for _, p := range parsers {
+		err = p.AddTag(tag, result)
+		if err != nil && err.ParseError == ErrTagDoesNotApply {
+			continue
+		} else if err != nil {
+			return err
+		}
+		applied = true
+	}
+	if applied {
+		return nil
+	}
+	return err
+}
+
 func (t *Transactions) Parse(input io.Reader) ([]Transaction, ParseError) {
 	data, err := ioutil.ReadAll(input)
 	if err != nil {
-		return nil, WrapParseError(err)
+		return nil, &IOError{err}
 	}
 
-	tagIndexes := tagRegex.FindAllIndex(data, -1)
-	if len(tagIndexes) == 0 {
-		return nil, ErrNoTagsFound
+	t.Raw = data
+	enc := t.Encoding
+	if enc == nil && t.AutoDetectEncoding {
+		t.DetectedEncoding = DetectEncoding(data)
+		enc = LookupEncoding(t.DetectedEncoding)
 	}
-	tr := &Transaction{}
-	for i, inds := range tagIndexes {
-		start := tagIndexes[i][0]
-		end := len(data)
-		if i+1 < len(tagIndexes) {
-			end = tagIndexes[i+1][0]
-		}
-		block := data[start:end]
-		// strip : off beginning and end
-		id := string(data[inds[0]+1 : inds[1]-1])
-		tag, ok := Tags[id]
-		if !ok {
-			return nil, &TagError{ErrNotExist, nil, id}
+	// Offsets into Raw of the bytes of data, nil while they're the same
+	var offsets []int
+	if enc != nil {
+		if data, offsets, err = decode(enc, data); err != nil {
+			return nil, &IOError{err}
 		}
+	}
 
-		result, err := tag.Parse(string(block))
-		if err != nil {
+	if t.Sanitize {
+		size := len(data)
+		data, t.Replacements = sanitize(data, t.SanitizeReplacement)
+		if err := checkInvalidRatio(t.Replacements, size, t.MaxInvalidRatio); err != nil {
 			return nil, err
 		}
+		if len(t.Replacements) > 0 {
+			sanitized := sanitizeOffsets(size, t.Replacements, t.SanitizeReplacement)
+			if offsets != nil {
+				for i, o := range sanitized {
+					sanitized[i] = offsets[o]
+				}
+			}
+			offsets = sanitized
+		}
+	}
 
-		if id == "20" && tr.TransactionReferenceNumber != "" {
-			t.transactions = append(t.transactions, *tr)
-			tr = &Transaction{}
+	registry := t.Registry
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+	split := splitLines(data)
+	classifyLines(split, registry.known)
+	tokens := tokenizeLines(split)
+	if len(tokens) == 0 {
+		return nil, ErrNoTagsFound
+	}
+
+	var validator *Validator
+	if t.MessageType != "" {
+		if validator, err = NewValidator(t.MessageType); err != nil {
+			return nil, WrapParseError(err)
 		}
+	}
 
-		{
-			parsers := []TagParser{
-				tr, t,
+	lines := newLineIndex(t.FileName, data)
+	var nodes []int
+	t.CST, nodes = newCST(lines, split, tokens, t.Raw, offsets)
+	tr := &Transaction{}
+	statementStart, resume := 0, 0
+
+	// Reports the mandatory tags missing at the end of the statement at its
+	// last tag and the network rule violations at their tags
+	finish := func() *TagError {
+		var errs []*TagError
+		if validator != nil {
+			for _, ve := range validator.End() {
+				errs = append(errs, &TagError{ParseError: ve, Pos: tr.pos})
 			}
-
-			var err *TagError
-			for _, p := range parsers {
-				err = p.AddTag(&tag, result)
-				if err != nil && err.ParseError == ErrTagDoesNotApply {
-					continue
+		}
+		if t.NetworkRules {
+			errs = append(errs, tr.CheckNetworkRules()...)
+			errs = append(errs, tr.CheckEntrySummaries()...)
+			errs = append(errs, tr.CheckFloorLimit()...)
+			errs = append(errs, tr.CheckAccountCurrency()...)
+		}
+		for _, te := range errs {
+			te.Source = lines.line(te.Pos.Line)
+			if !t.Lenient {
+				return te
+			}
+			t.Diagnostics = append(t.Diagnostics, te)
+		}
+		return nil
+	}
+	for i, tok := range tokens {
+		if i < resume {
+			continue
+		}
+		pos := lines.position(tok.start, tok.end)
+		locate := func(te *TagError) *TagError {
+			te.Pos = pos
+			te.Source = lines.line(pos.Line)
+			return te
+		}
+		id, block := tok.id, tok.value
+
+		if id == "20" {
+			if tr.TransactionReferenceNumber != "" {
+				if err := finish(); err == nil {
+					t.transactions = append(t.transactions, *tr)
+				} else if t.Recover {
+					t.Skipped = append(t.Skipped, SkippedRange{lines.position(statementStart, tok.start), err})
 				} else {
-					break
+					return nil, err
+				}
+				tr = &Transaction{}
+			}
+			statementStart = tok.start
+		}
+
+		// Hands tags the parser can't process to OnUnknownTag
+		unsupported := func(te *TagError) *TagError {
+			if t.OnUnknownTag == nil {
+				return locate(te)
+			}
+			switch err := t.OnUnknownTag(id, block, pos); {
+			case err == nil:
+				tr.Unknown = append(tr.Unknown, ExtraTag{ID: id, Value: block})
+			case !errors.Is(err, ErrIgnoreTag):
+				return locate(&TagError{ParseError: WrapParseError(err), Tag: te.Tag, Value: block})
+			}
+			return nil
+		}
+
+		process := func() *TagError {
+			tag, ok := registry.lookup(id)
+			if !ok {
+				return unsupported(&TagError{ParseError: &UnknownTagError{id}, Value: id})
+			}
+
+			result, spans, err := tag.parse(block)
+			if err != nil && err.ParseError == ErrNotImplemented {
+				return unsupported(err)
+			}
+			if err != nil {
+				return locate(err)
+			}
+			t.CST.Nodes[nodes[i]].addFields(lines, tok, result, spans)
+			for _, fe := range tag.CheckLimits(result) {
+				te := locate(&TagError{ParseError: fe, Tag: &tag, Value: block})
+				if !t.Lenient {
+					return te
+				}
+				t.Diagnostics = append(t.Diagnostics, te)
+			}
+			if validator != nil {
+				for _, ve := range validator.Next(id) {
+					te := locate(&TagError{ParseError: ve, Tag: &tag, Value: block})
+					if !t.Lenient {
+						return te
+					}
+					t.Diagnostics = append(t.Diagnostics, te)
 				}
 			}
 
+			tr.pos = pos
+			err = t.addTag(tr, &tag, result)
+			if _, std := Tags[id]; !std && err != nil && err.ParseError == ErrTagDoesNotApply {
+				tr.Extra = append(tr.Extra, ExtraTag{id, block, result})
+				return nil
+			}
+			if err != nil && err.ParseError == ErrTagDoesNotApply {
+				return unsupported(err)
+			}
 			if err != nil {
+				return locate(err)
+			}
+			return nil
+		}
+
+		if err := process(); err != nil {
+			if !t.Recover {
 				return nil, err
 			}
+
+			resume = resync(tokens, i)
+			skipEnd := len(data)
+			if resume < len(tokens) {
+				skipEnd = tokens[resume].start
+			}
+			t.Skipped = append(t.Skipped, SkippedRange{lines.position(statementStart, skipEnd), err})
+			tr = &Transaction{}
+			statementStart = skipEnd
+			if validator != nil {
+				validator.End()
+			}
+		}
+	}
+
+	if tr.pos.IsValid() {
+		if err := finish(); err == nil {
+			t.transactions = append(t.transactions, *tr)
+		} else if t.Recover {
+			t.Skipped = append(t.Skipped, SkippedRange{lines.position(statementStart, len(data)), err})
+		} else {
+			return nil, err
 		}
 	}
 
@@ -21,6 +21,41 @@ func newTransactionDate(t time.Time) TransactionDate {
 	return TransactionDate{Time: &t}
 }
 
+// The parsed values of a statement and its entries, without the positions and
+// the unexported bookkeeping the expectations below don't describe
+func summarize(tr Transaction) Transaction {
+	balance := func(b Balance) Balance {
+		return Balance{Timestamp: b.Timestamp, Status: b.Status, Amount: b.Amount, Currency: b.Currency}
+	}
+	statementLine := func(sl StatementLine) StatementLine {
+		sl.decimals = 0
+		return sl
+	}
+	sum := tr
+	sum.StatementLine = statementLine(tr.StatementLine)
+	sum.FinalOpeningBalance = balance(tr.FinalOpeningBalance)
+	sum.AvailableBalance = balance(tr.AvailableBalance)
+	sum.FinalClosingBalance = balance(tr.FinalClosingBalance)
+	sum.IntermediateOpeningBalance = balance(tr.IntermediateOpeningBalance)
+	sum.IntermediateClosingBalance = balance(tr.IntermediateClosingBalance)
+	sum.ForwardAvailableBalance = nil
+	for _, b := range tr.ForwardAvailableBalance {
+		sum.ForwardAvailableBalance = append(sum.ForwardAvailableBalance, balance(b))
+	}
+	sum.Entries = nil
+	for _, e := range tr.Entries {
+		sum.Entries = append(sum.Entries, Entry{
+			StatementLine:      statementLine(e.StatementLine),
+			TransactionDetails: e.TransactionDetails,
+			NonSwift:           e.NonSwift,
+		})
+	}
+	sum.Positions = nil
+	sum.closed = false
+	sum.pos = Position{}
+	return sum
+}
+
 func TestTransactions_Parse(t *testing.T) {
 	type args struct {
 		input io.Reader
@@ -28,6 +63,7 @@ func TestTransactions_Parse(t *testing.T) {
 	tests := []struct {
 		name    string
 		args    args
+		lenient bool
 		want    []Transaction
 		wantErr bool
 	}{
@@ -36,239 +72,400 @@ func TestTransactions_Parse(t *testing.T) {
 			args: args{
 				input: must(os.Open("ASNB/0708271685_09022020_164516.940.txt")),
 			},
+			// ASNB puts the full IBAN in the 16x customer reference
+			lenient: true,
 			want: []Transaction{
 				Transaction{
 					StatementLine: StatementLine{
-						Timestamp: newTransactionDate(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)), Status: "D", FundsCode: "", TransactionTypeID: "NOVB", CustomerReference: "NL47INGB9999999999\nhr gjlm pauli", BankReference: "", ExtraDetails: "ssen", Amount: Amount{65}},
+						Timestamp: newTransactionDate(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)), Status: "D", FundsCode: "", TransactionTypeID: "NOVB", CustomerReference: "NL47INGB9999999999", BankReference: "", ExtraDetails: "hr gjlm paulissen", Amount: NewAmount(6500)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR}, TransactionDetails: "NL47INGB9999999999 hr gjlm paulissen\n                                                                 \nBetaling sieraden"},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "1",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(44429), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(37929), Currency: currency.EUR},
+					TransactionDetails:         "NL47INGB9999999999 hr gjlm paulissen\n                                                                 \nBetaling sieraden",
+					Entries: []Entry{
+						{
+							StatementLine: StatementLine{
+								Timestamp: newTransactionDate(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)), Status: "D", FundsCode: "", TransactionTypeID: "NOVB", CustomerReference: "NL47INGB9999999999", BankReference: "", ExtraDetails: "hr gjlm paulissen", Amount: NewAmount(6500)},
+							TransactionDetails: "NL47INGB9999999999 hr gjlm paulissen\n                                                                 \nBetaling sieraden",
+						},
+					},
+				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "2",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(37929), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(37929), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 3, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 3, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "3",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 3, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(37929), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 3, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(37929), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 4, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 4, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "4",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 4, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(37929), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 4, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(37929), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Timestamp: newTransactionDate(time.Date(2020, time.January, 5, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 5, 0, 0, 0, 0, time.UTC)), Status: "D", FundsCode: "", TransactionTypeID: "NIDB", CustomerReference: "NL08ABNA9999999999\ninternational", BankReference: "", ExtraDetails: " card services", Amount: Amount{80155}},
+						Timestamp: newTransactionDate(time.Date(2020, time.January, 5, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 5, 0, 0, 0, 0, time.UTC)), Status: "D", FundsCode: "", TransactionTypeID: "NIDB", CustomerReference: "NL08ABNA9999999999", BankReference: "", ExtraDetails: "international card services", Amount: NewAmount(80155)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 5, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 5, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR}, TransactionDetails: "NL08ABNA9999999999 international card services \n                                                                 \n000000000000000000000000000000000 0000000000000000 Betaling aan I\nCS 99999999999 ICS Referentie: 2020-01-05 19:47 000000000000000"},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "5",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 5, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(37929), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 5, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					TransactionDetails:         "NL08ABNA9999999999 international card services \n                                                                 \n000000000000000000000000000000000 0000000000000000 Betaling aan I\nCS 99999999999 ICS Referentie: 2020-01-05 19:47 000000000000000",
+					Entries: []Entry{
+						{
+							StatementLine: StatementLine{
+								Timestamp: newTransactionDate(time.Date(2020, time.January, 5, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 5, 0, 0, 0, 0, time.UTC)), Status: "C", FundsCode: "", TransactionTypeID: "NIOB", CustomerReference: "NL56ASNB9999999999", BankReference: "", ExtraDetails: "paulissen g j l m", Amount: NewAmount(100000)},
+							TransactionDetails: "NL56ASNB9999999999 paulissen g j l m\n                                                                 \nINTERNE OVERBOEKING VIA MOBIEL",
+						},
+						{
+							StatementLine: StatementLine{
+								Timestamp: newTransactionDate(time.Date(2020, time.January, 5, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 5, 0, 0, 0, 0, time.UTC)), Status: "D", FundsCode: "", TransactionTypeID: "NIDB", CustomerReference: "NL08ABNA9999999999", BankReference: "", ExtraDetails: "international card services", Amount: NewAmount(80155)},
+							TransactionDetails: "NL08ABNA9999999999 international card services \n                                                                 \n000000000000000000000000000000000 0000000000000000 Betaling aan I\nCS 99999999999 ICS Referentie: 2020-01-05 19:47 000000000000000",
+						},
+					},
+				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 6, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 6, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "6",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 6, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 6, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 7, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 7, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "7",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 7, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 7, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 8, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 8, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "8",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 8, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 8, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 9, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 9, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "9",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 9, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 9, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 10, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 10, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "10",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 10, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 10, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 11, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 11, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "11",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 11, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 11, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 12, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 12, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "12",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 12, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 12, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 13, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 13, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "13",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 13, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 13, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 14, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 14, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "14",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 14, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 14, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 15, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 15, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "15",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 15, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 15, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 16, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 16, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "16",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 16, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 16, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 17, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 17, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "17",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 17, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 17, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 18, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 18, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "18",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 18, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 18, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 19, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 19, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "19",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 19, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 19, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 20, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 20, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "20",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 20, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 20, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 21, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 21, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "21",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 21, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 21, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 22, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 22, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "22",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 22, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 22, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 23, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 23, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "23",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 23, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 23, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 24, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 24, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "24",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 24, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 24, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Timestamp: newTransactionDate(time.Date(2020, time.January, 25, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 25, 0, 0, 0, 0, time.UTC)), Status: "D", FundsCode: "", TransactionTypeID: "NDIV", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{165}},
+						Timestamp: newTransactionDate(time.Date(2020, time.January, 25, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 25, 0, 0, 0, 0, time.UTC)), Status: "D", FundsCode: "", TransactionTypeID: "NDIV", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(165)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 25, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 25, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR}, TransactionDetails: "Kosten gebruik betaalrekening inclusief 1 betaalpas"},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "25",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 25, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57774), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 25, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57609), Currency: currency.EUR},
+					TransactionDetails:         "Kosten gebruik betaalrekening inclusief 1 betaalpas",
+					Entries: []Entry{
+						{
+							StatementLine: StatementLine{
+								Timestamp: newTransactionDate(time.Date(2020, time.January, 25, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 25, 0, 0, 0, 0, time.UTC)), Status: "D", FundsCode: "", TransactionTypeID: "NDIV", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(165)},
+							TransactionDetails: "Kosten gebruik betaalrekening inclusief 1 betaalpas",
+						},
+					},
+				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 26, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 26, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "26",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 26, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57609), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 26, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57609), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 27, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 27, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "27",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 27, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57609), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 27, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57609), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 28, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 28, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "28",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 28, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57609), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 28, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57609), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
 				Transaction{
 					StatementLine: StatementLine{
-						Timestamp: newTransactionDate(time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC)), Status: "D", FundsCode: "", TransactionTypeID: "NIDB", CustomerReference: "NL08ABNA9999999999\ninternational", BankReference: "", ExtraDetails: " card services", Amount: Amount{100000}},
+						Timestamp: newTransactionDate(time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC)), Status: "D", FundsCode: "", TransactionTypeID: "NIDB", CustomerReference: "NL08ABNA9999999999", BankReference: "", ExtraDetails: "international card services", Amount: NewAmount(100000)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR}, TransactionDetails: "NL08ABNA9999999999 international card services \n                                                                 \n000000000000000000000000000000000 0000000000000000 Betaling aan I\nCS 99999999999 ICS Referentie: 2020-01-29 18:36 000000000000000"},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "29",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(57609), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(40481), Currency: currency.EUR},
+					TransactionDetails:         "NL08ABNA9999999999 international card services \n                                                                 \n000000000000000000000000000000000 0000000000000000 Betaling aan I\nCS 99999999999 ICS Referentie: 2020-01-29 18:36 000000000000000",
+					Entries: []Entry{
+						{
+							StatementLine: StatementLine{
+								Timestamp: newTransactionDate(time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC)), Status: "C", FundsCode: "", TransactionTypeID: "NOVB", CustomerReference: "NL25INGB9999999999", BankReference: "", ExtraDetails: "transfer solutions bv", Amount: NewAmount(82872)},
+							TransactionDetails: "NL25INGB9999999999 transfer solutions bv\n                                                                 \n2020-01-28T14:32:46-000000000000089-NL25INGB9999999999-Transfer S\nolutions BV-DIVIDEND 28/01/2020",
+						},
+						{
+							StatementLine: StatementLine{
+								Timestamp: newTransactionDate(time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC)), Status: "D", FundsCode: "", TransactionTypeID: "NIDB", CustomerReference: "NL08ABNA9999999999", BankReference: "", ExtraDetails: "international card services", Amount: NewAmount(100000)},
+							TransactionDetails: "NL08ABNA9999999999 international card services \n                                                                 \n000000000000000000000000000000000 0000000000000000 Betaling aan I\nCS 99999999999 ICS Referentie: 2020-01-29 18:36 000000000000000",
+						},
+					},
+				},
 				Transaction{
 					StatementLine: StatementLine{
-						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: Amount{0}},
+						Status: "", FundsCode: "", TransactionTypeID: "", CustomerReference: "", BankReference: "", ExtraDetails: "", Amount: NewAmount(0)},
 					TransactionReferenceNumber: "0000000000",
-					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 30, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
-					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 30, 0, 0, 0, 0, time.UTC)), Status: "", Amount: Amount{0}, Currency: currency.EUR},
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "30",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 30, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(40481), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 30, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(40481), Currency: currency.EUR},
 					TransactionDetails:         "",
 				},
+				Transaction{
+					StatementLine: StatementLine{
+						Timestamp: newTransactionDate(time.Date(2020, time.January, 31, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 31, 0, 0, 0, 0, time.UTC)), Status: "D", FundsCode: "", TransactionTypeID: "NIDB", CustomerReference: "NL08ABNA9999999999", BankReference: "", ExtraDetails: "international card services", Amount: NewAmount(90376)},
+					TransactionReferenceNumber: "0000000000",
+					AccountIdentification:      "NL81ASNB9999999999",
+					StatementNumber:            "31",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 31, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(40481), Currency: currency.EUR},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2020, time.January, 31, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(50123), Currency: currency.EUR},
+					TransactionDetails:         "NL08ABNA9999999999 international card services \n                                                                 \n000000000000000000000000000000000 0000000000000000 Betaling aan I\nCS 99999999999 ICS Referentie: 2020-01-31 21:27 000000000000000",
+					Entries: []Entry{
+						{
+							StatementLine: StatementLine{
+								Timestamp: newTransactionDate(time.Date(2020, time.January, 31, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 31, 0, 0, 0, 0, time.UTC)), Status: "C", FundsCode: "", TransactionTypeID: "NIOB", CustomerReference: "NL56ASNB9999999999", BankReference: "", ExtraDetails: "paulissen g j l m", Amount: NewAmount(100018)},
+							TransactionDetails: "NL56ASNB9999999999 paulissen g j l m\n                                                                 \nINTERNE OVERBOEKING VIA MOBIEL",
+						},
+						{
+							StatementLine: StatementLine{
+								Timestamp: newTransactionDate(time.Date(2020, time.January, 31, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2020, time.January, 31, 0, 0, 0, 0, time.UTC)), Status: "D", FundsCode: "", TransactionTypeID: "NIDB", CustomerReference: "NL08ABNA9999999999", BankReference: "", ExtraDetails: "international card services", Amount: NewAmount(90376)},
+							TransactionDetails: "NL08ABNA9999999999 international card services \n                                                                 \n000000000000000000000000000000000 0000000000000000 Betaling aan I\nCS 99999999999 ICS Referentie: 2020-01-31 21:27 000000000000000",
+						},
+					},
+				},
 			},
 			wantErr: false,
 		},
@@ -277,28 +474,93 @@ func TestTransactions_Parse(t *testing.T) {
 			args: args{
 				input: must(os.Open("mBank/mt940.sta")),
 			},
-			want:    []Transaction{},
+			want: []Transaction{
+				Transaction{
+					StatementLine: StatementLine{
+						Timestamp: newTransactionDate(time.Date(2017, time.January, 19, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2017, time.January, 19, 0, 0, 0, 0, time.UTC)), Status: "C", FundsCode: "N", TransactionTypeID: "NTRF", CustomerReference: "NONREF", BankReference: "MB170119012121", ExtraDetails: "911-TRANSAKCJA IPH", Amount: NewAmount(1)},
+					TransactionReferenceNumber: "ST170119CYC/1",
+					AccountIdentification:      "PL29114010810000267002001002",
+					StatementNumber:            "1",
+					StatementSeqNumber:         "1",
+					FinalOpeningBalance:        Balance{Timestamp: newTransactionDate(time.Date(2017, time.January, 19, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(40), Currency: currency.PLN},
+					AvailableBalance:           Balance{Timestamp: newTransactionDate(time.Date(2017, time.January, 19, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(43), Currency: currency.PLN},
+					FinalClosingBalance:        Balance{Timestamp: newTransactionDate(time.Date(2017, time.January, 19, 0, 0, 0, 0, time.UTC)), Status: "C", Amount: NewAmount(43), Currency: currency.PLN},
+					TransactionDetails:         "911 TRANSAKCJA COLLECT; ID IPH: XX000000000003; Z RACH.: \n56114010810000267002001001; OD: JAN NOWAK  \nUL. NIJAKA 1 M 2 31-234 KRAKOW; TYT.: PRZELEW SRODKOW   ; \nTNR: 179171073864291.000001",
+					Entries: []Entry{
+						{
+							StatementLine: StatementLine{
+								Timestamp: newTransactionDate(time.Date(2017, time.January, 19, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2017, time.January, 19, 0, 0, 0, 0, time.UTC)), Status: "C", FundsCode: "N", TransactionTypeID: "NTRF", CustomerReference: "NONREF", BankReference: "MB170119012058", ExtraDetails: "911-TRANSAKCJA IPH", Amount: NewAmount(1)},
+							TransactionDetails: "911 TRANSAKCJA COLLECT; ID IPH: XX000000000001; Z RACH.: \n56114010810000267002001001; OD: JAN NOWAK  \nUL. NIJAKA 1 M 2 31-234 KRAKOW; TYT.: PRZELEW SRODKOW   ; \nTNR: 179171073864111.010001",
+						},
+						{
+							StatementLine: StatementLine{
+								Timestamp: newTransactionDate(time.Date(2017, time.January, 19, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2017, time.January, 19, 0, 0, 0, 0, time.UTC)), Status: "C", FundsCode: "N", TransactionTypeID: "NTRF", CustomerReference: "NONREF", BankReference: "MB170119012085", ExtraDetails: "911-TRANSAKCJA IPH", Amount: NewAmount(1)},
+							TransactionDetails: "911 TRANSAKCJA COLLECT; ID IPH: XX000000000002; Z RACH.: \n56114010810000267002001001; OD: JAN NOWAK  \nUL. NIJAKA 1 M 2 31-234 KRAKOW; TYT.: PRZELEW SRODKOW   ; \nTNR: 179171073864192.000001",
+						},
+						{
+							StatementLine: StatementLine{
+								Timestamp: newTransactionDate(time.Date(2017, time.January, 19, 0, 0, 0, 0, time.UTC)), EntryTime: newTransactionDate(time.Date(2017, time.January, 19, 0, 0, 0, 0, time.UTC)), Status: "C", FundsCode: "N", TransactionTypeID: "NTRF", CustomerReference: "NONREF", BankReference: "MB170119012121", ExtraDetails: "911-TRANSAKCJA IPH", Amount: NewAmount(1)},
+							TransactionDetails: "911 TRANSAKCJA COLLECT; ID IPH: XX000000000003; Z RACH.: \n56114010810000267002001001; OD: JAN NOWAK  \nUL. NIJAKA 1 M 2 31-234 KRAKOW; TYT.: PRZELEW SRODKOW   ; \nTNR: 179171073864291.000001",
+						},
+					},
+				},
+			},
 			wantErr: false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tr := &Transactions{}
+			tr := &Transactions{Lenient: tt.lenient}
 			got, err := tr.Parse(tt.args.input)
 			if (err != nil) != tt.wantErr {
 				t.Error(err)
 
 				return
 			}
+			if tt.lenient && len(tr.Diagnostics) == 0 {
+				t.Errorf("Transactions.Parse() expected length diagnostics")
+			}
 			if len(got) != len(tt.want) {
 				t.Errorf("Transactions.Parse() len(results) = %v, want %v", len(got), len(tt.want))
 				t.Errorf("%#v", got)
 			}
 			for i, trans := range got {
-				if reflect.DeepEqual(trans, tt.want[i]) {
-					t.Errorf("Transactions not equal: got[%v] = %v, want[%v] = %v", i, trans, i, tt.want[i])
+				if i < len(tt.want) && !reflect.DeepEqual(summarize(trans), tt.want[i]) {
+					t.Errorf("Transactions not equal: got[%v] = %+v, want[%v] = %+v", i, summarize(trans), i, tt.want[i])
 				}
 			}
 		})
 	}
 }
+
+func TestTransactions_Parse_MT942(t *testing.T) {
+	tests := []struct {
+		file       string
+		floorLimit FloorLimit
+		created    string
+	}{
+		{"mBank/mt942.sta", FloorLimit{NewAmount(0), NewAmount(0), currency.PLN}, "2017-01-19T18:15:00+01:00"},
+		{"self-provided/mt942.sta", FloorLimit{NewAmount(0), NewAmount(0), currency.EUR}, "2016-10-30T17:30:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			tr := &Transactions{MessageType: MT942}
+			got, err := tr.Parse(must(os.Open(tt.file)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("Transactions.Parse() len(results) = %v, want 1", len(got))
+			}
+			if fl := got[0].FloorLimit; fl == nil || *fl != tt.floorLimit {
+				t.Errorf("FloorLimit = %+v, want %+v", fl, tt.floorLimit)
+			}
+			if created := got[0].DateTimeIndication; created == nil || created.Format(time.RFC3339) != tt.created {
+				t.Errorf("DateTimeIndication = %v, want %v", created, tt.created)
+			}
+			if len(got[0].Entries) == 0 || len(got[0].Unknown) != 0 {
+				t.Errorf("Transactions.Parse() = %v entries, unknown tags %v", len(got[0].Entries), got[0].Unknown)
+			}
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package mt940
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -9,15 +14,22 @@ func TestAmount_Parse(t *testing.T) {
 	tests := []struct {
 		name    string
 		args    string
-		amount  int64
+		amount  int64 // Thousandths
 		wantErr bool
 	}{
 		{
 			"Basic",
 			"123,23",
-			12323,
+			123230,
 			false,
 		},
+		{"One decimal", "2,5", 2500, false},
+		{"No decimals", "100,", 100000, false},
+		{"No comma", "960", 960000, false},
+		{"Minor units", "1,125", 1125, false},
+		{"Trailing zeros", "1,12500", 1125, false},
+		{"Beyond minor units", "1,1255", 1125, false},
+		{"Misformatted", "1,2,3", 0, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -60,3 +72,334 @@ func TestTransactionDate_Parse(t *testing.T) {
 		})
 	}
 }
+
+func addTags(t *testing.T, tr TagParser, values ...string) {
+	t.Helper()
+	for _, value := range values {
+		match := tagRegex.FindStringSubmatch(value)
+		tag := Tags[match[1]]
+		result, err := tag.Parse(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tr.AddTag(&tag, result); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTransaction_AddTag_NonSwift(t *testing.T) {
+	tr := &Transaction{}
+	addTags(t, tr,
+		":20:STARTUMS",
+		":NS:22JOHN DOE\n23John Doe\n25171004171011",
+		":60F:C171011HUF627311,30",
+		":61:1710111011DF2402,00S   X",
+		":NS:01526715\n09Tranzakcios Illetek:7.21HUF\n15ERGO Versicherung AG Fiokte\n16lep\n15second",
+		":62F:C171011HUF617874,30",
+	)
+
+	want := NonSwift{"22": {"JOHN DOE"}, "23": {"John Doe"}, "25": {"171004171011"}}
+	if !reflect.DeepEqual(tr.NonSwift, want) {
+		t.Errorf("statement NonSwift = %v, want %v", tr.NonSwift, want)
+	}
+	// Only the values of a repeated code are ordered, 15 appears twice
+	want = NonSwift{
+		"01": {"526715"},
+		"09": {"Tranzakcios Illetek:7.21HUF"},
+		"15": {"ERGO Versicherung AG Fiokte", "second"},
+		"16": {"lep"},
+	}
+	if len(tr.Entries) != 1 || !reflect.DeepEqual(tr.Entries[0].NonSwift, want) {
+		t.Errorf("entry NonSwift = %v, want %v", tr.Entries, want)
+	}
+
+	named := tr.Entries[0].NonSwift.Named(NonSwiftProfile{"15": "counterparty_name"})
+	if !reflect.DeepEqual(named["counterparty_name"], want["15"]) || named["01"] == nil {
+		t.Errorf("Named() = %v", named)
+	}
+}
+
+func TestTransaction_AddTag_EntryTime(t *testing.T) {
+	tr := &Transaction{}
+	addTags(t, tr,
+		":20:REF",
+		":61:2001010102D1,00NTRFNONREF",
+		":61:200101D2,00NTRFNONREF",
+	)
+	if e := tr.Entries[0].EntryTime; e.Time == nil || e.Day() != 2 {
+		t.Errorf("Entries[0].EntryTime = %v, want January 2", e.Time)
+	}
+	if e := tr.Entries[1].EntryTime; e.Time != nil {
+		t.Errorf("Entries[1].EntryTime = %v, want none", e.Time)
+	}
+}
+
+func TestTransaction_AddTag_Information(t *testing.T) {
+	tr := &Transaction{}
+	addTags(t, tr,
+		":20:STARTUMS",
+		":60F:C171011HUF627311,30",
+		":86:header",
+		":61:1710111011DF2402,00S   X",
+		":86:entry details",
+		":62F:C171011HUF617874,30",
+		":86:first remark",
+		":86:second remark",
+	)
+
+	if tr.HeaderInformation != "header" {
+		t.Errorf("HeaderInformation = %q", tr.HeaderInformation)
+	}
+	if tr.Information != "first remark\nsecond remark" {
+		t.Errorf("Information = %q", tr.Information)
+	}
+	if tr.TransactionDetails != "entry details" || tr.Entries[0].TransactionDetails != "entry details" {
+		t.Errorf("TransactionDetails = %q, entry = %q", tr.TransactionDetails, tr.Entries[0].TransactionDetails)
+	}
+}
+
+func TestTransactions_Parse_Lenient(t *testing.T) {
+	_, err := (&Transactions{}).Parse(must(os.Open("self-provided/overly_long_details.sta")))
+	if te, ok := err.(*TagError); !ok || te.id != "86" {
+		t.Fatalf("Transactions.Parse() error = %v, want field error on :86:", err)
+	}
+
+	tr := &Transactions{Lenient: true}
+	if _, err := tr.Parse(must(os.Open("self-provided/overly_long_details.sta"))); err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.Diagnostics) != 1 {
+		t.Fatalf("Diagnostics = %v, want 1", tr.Diagnostics)
+	}
+	var fe *FieldError
+	if !errors.As(tr.Diagnostics, &fe) || fe.Length != 9 || fe.Max != 6 || strings.Count(fe.Value, "\n") != 8 {
+		t.Errorf("Diagnostics[0] = %v, want 9 lines of full value", tr.Diagnostics[0])
+	}
+}
+
+func TestTransactions_Parse_LongAmount(t *testing.T) {
+	input := ":20:REF\n:60F:C200101EUR1,00\n:62F:C200101EUR1234567890123456,00\n"
+	var fe *FieldError
+	if _, err := (&Transactions{}).Parse(strings.NewReader(input)); !errors.As(err, &fe) || fe.Field != "amount" {
+		t.Errorf("Transactions.Parse() error = %v, want a field error on the amount", err)
+	}
+
+	tr := &Transactions{Lenient: true}
+	got, err := tr.Parse(strings.NewReader(input))
+	if err != nil || len(tr.Diagnostics) != 1 {
+		t.Fatalf("Transactions.Parse() error = %v, diagnostics %v, want 1", err, tr.Diagnostics)
+	}
+	if got[0].FinalClosingBalance.Hundredths() != 123456789012345600 {
+		t.Errorf("FinalClosingBalance = %v, want the full amount", got[0].FinalClosingBalance.Hundredths())
+	}
+}
+
+func TestTransactions_Parse_Positions(t *testing.T) {
+	input := ":20:STARTUMS\n:60F:C171011HUF627311,30\n:61:1710111011DF2402,00S   X\n:86:details\n:20:NEXT\n:25:" +
+		strings.Repeat("1", 36) + "\n"
+
+	tr := &Transactions{FileName: "test.sta"}
+	_, err := tr.Parse(strings.NewReader(input))
+	te, ok := err.(*TagError)
+	if !ok {
+		t.Fatalf("Transactions.Parse() error = %v, want *TagError", err)
+	}
+	want := Position{"test.sta", 6, 1, 88, len(input)}
+	if te.Pos != want {
+		t.Errorf("TagError.Pos = %#v, want %#v", te.Pos, want)
+	}
+	if !strings.HasPrefix(te.Error(), "test.sta:6:1: ") || !strings.HasSuffix(te.Error(), "\n:25:"+strings.Repeat("1", 36)+"\n^") {
+		t.Errorf("TagError.Error() = %q", te.Error())
+	}
+
+	tr = &Transactions{FileName: "test.sta", Lenient: true}
+	got, err := tr.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := got[0].Positions["60F"]; p != (Position{"test.sta", 2, 1, 13, 38}) {
+		t.Errorf("Positions[60F] = %#v", p)
+	}
+	if p := got[0].Entries[0].Positions["86"]; p != (Position{"test.sta", 4, 1, 67, 79}) {
+		t.Errorf("Entries[0].Positions[86] = %#v", p)
+	}
+}
+
+func TestTransactions_Parse_Recover(t *testing.T) {
+	input := ":20:FIRST\n:60F:C171011HUF1,00\n:62F:C171011HUF1,00\n-\n" +
+		":20:BROKEN\n:60F:C171011HUF1,00\n:99:unknown\n:62F:C171011HUF1,00\n-\n" +
+		":25:NO REFERENCE\n:60F:X\n-\n" +
+		":20:BAD AMOUNT\n:60F:C171011HUF1,00\n:61:171011D1,,5NTRFNONREF\n-\n" +
+		":20:LAST\n:60F:C171011HUF1,00\n:62F:C171011HUF1,00\n"
+
+	if _, err := (&Transactions{}).Parse(strings.NewReader(input)); err == nil {
+		t.Fatal("Transactions.Parse() without Recover should fail")
+	}
+
+	tr := &Transactions{Recover: true}
+	got, err := tr.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].TransactionReferenceNumber != "FIRST" || got[1].TransactionReferenceNumber != "LAST" {
+		t.Fatalf("Transactions.Parse() = %v, want FIRST and LAST", got)
+	}
+
+	want := []struct {
+		skipped string
+		err     error
+	}{
+		{":20:BROKEN\n:60F:C171011HUF1,00\n:99:unknown\n:62F:C171011HUF1,00\n-\n", ErrNotExist},
+		{":25:NO REFERENCE\n:60F:X\n-\n", ErrTagDidNotParse},
+		{":20:BAD AMOUNT\n:60F:C171011HUF1,00\n:61:171011D1,,5NTRFNONREF\n-\n", ErrMisformatedTag},
+	}
+	if len(tr.Skipped) != len(want) {
+		t.Fatalf("Skipped = %v, want %v entries", tr.Skipped, len(want))
+	}
+	for i, s := range tr.Skipped {
+		if skipped := input[s.Pos.Offset:s.Pos.End]; skipped != want[i].skipped {
+			t.Errorf("Skipped[%v] = %q, want %q", i, skipped, want[i].skipped)
+		}
+		if !errors.Is(s.Err, want[i].err) {
+			t.Errorf("Skipped[%v].Err = %v, want %v", i, s.Err, want[i].err)
+		}
+	}
+}
+
+func TestTransactions_Parse_RecoverFixtures(t *testing.T) {
+	tests := []struct {
+		file    string
+		parsed  int
+		skipped int
+	}{
+		{"betterplace/sepa_snippet_broken.sta", 0, 1},
+		{"jejik/abnamro.sta", 1, 1},
+		{"self-provided/february_30.sta", 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			tr := &Transactions{Recover: true}
+			got, err := tr.Parse(must(os.Open(tt.file)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.parsed || len(tr.Skipped) != tt.skipped {
+				t.Errorf("Transactions.Parse() parsed %v skipped %v, want %v and %v", len(got), len(tr.Skipped), tt.parsed, tt.skipped)
+				for _, s := range tr.Skipped {
+					t.Log(s.Pos, s.Err)
+				}
+			}
+		})
+	}
+}
+
+func TestTransactions_Parse_OnUnknownTag(t *testing.T) {
+	input := ":20:REF\n:60F:C171011EUR1,00\n:99:PROPRIETARY\n:62F:C171011EUR1,00\n:90:3EUR1,00\n:13:1701191815\n"
+	fail := errors.New("refused")
+
+	tests := []struct {
+		name    string
+		handle  map[string]error
+		want    []ExtraTag
+		wantErr error
+	}{
+		{"collect", map[string]error{}, []ExtraTag{
+			{ID: "99", Value: ":99:PROPRIETARY"},
+			{ID: "90", Value: ":90:3EUR1,00"},
+			{ID: "13", Value: ":13:1701191815"},
+		}, nil},
+		{"ignore", map[string]error{"99": ErrIgnoreTag, "90": fmt.Errorf("balance: %w", ErrIgnoreTag)}, []ExtraTag{
+			{ID: "13", Value: ":13:1701191815"},
+		}, nil},
+		{"fail", map[string]error{"90": fail}, nil, fail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lines []int
+			tr := &Transactions{OnUnknownTag: func(id, raw string, pos Position) error {
+				lines = append(lines, pos.Line)
+				return tt.handle[id]
+			}}
+			got, err := tr.Parse(strings.NewReader(input))
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("Transactions.Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got[0].Unknown, tt.want) {
+				t.Errorf("Unknown = %v, want %v", got[0].Unknown, tt.want)
+			}
+			if !reflect.DeepEqual(lines, []int{3, 5, 6}) {
+				t.Errorf("OnUnknownTag() called on lines %v, want 3, 5 and 6", lines)
+			}
+		})
+	}
+
+	if _, err := (&Transactions{}).Parse(strings.NewReader(input)); !errors.Is(err, ErrNotExist) {
+		t.Errorf("Transactions.Parse() without OnUnknownTag error = %v, want %v", err, ErrNotExist)
+	}
+}
+
+func TestRelated(t *testing.T) {
+	input := ":20:FIRST\n:21:REQ1\n:60F:C171011EUR1,00\n:62F:C171011EUR1,00\n-\n" +
+		":20:SECOND\n:21:NONREF\n:60F:C171011EUR1,00\n:62F:C171011EUR1,00\n-\n" +
+		":20:THIRD\n:21:REQ1\n:60F:C171011EUR1,00\n:62F:C171011EUR1,00\n"
+	got, err := (&Transactions{}).Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].RelatedReference != "REQ1" || got[1].RelatedReference != "NONREF" {
+		t.Fatalf("RelatedReference = %q, %q, want REQ1 and NONREF", got[0].RelatedReference, got[1].RelatedReference)
+	}
+
+	tests := []struct {
+		reference string
+		want      []string
+	}{
+		{"REQ1", []string{"FIRST", "THIRD"}},
+		{"REQ2", nil},
+		{"NONREF", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			var refs []string
+			for _, tr := range Related(got, tt.reference) {
+				refs = append(refs, tr.TransactionReferenceNumber)
+			}
+			if !reflect.DeepEqual(refs, tt.want) {
+				t.Errorf("Related() = %v, want %v", refs, tt.want)
+			}
+		})
+	}
+}
+
+func TestByAccount(t *testing.T) {
+	input := ":20:FIRST\n:25:ACCOUNT1\n:28C:1\n:60F:C171011EUR1,00\n:62F:C171011EUR1,00\n-\n" +
+		":20:SECOND\n:25:ACCOUNT2\n:28C:1\n:60F:C171011EUR1,00\n:62F:C171011EUR1,00\n-\n" +
+		":20:THIRD\n:25:ACCOUNT1\n:28C:2\n:60F:C171011EUR1,00\n:62F:C171011EUR1,00\n-\n" +
+		":20:FOURTH\n:60F:C171011EUR1,00\n:62F:C171011EUR1,00\n"
+	tr := &Transactions{}
+	got, err := tr.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.AccountIdentification != "ACCOUNT1" || got[1].AccountIdentification != "ACCOUNT2" {
+		t.Fatalf("AccountIdentification = %q, statement 2 %q", tr.AccountIdentification, got[1].AccountIdentification)
+	}
+
+	grouped := make(map[string][]string)
+	var accounts []string
+	for _, a := range ByAccount(got) {
+		accounts = append(accounts, a.Account)
+		for _, s := range a.Statements {
+			grouped[a.Account] = append(grouped[a.Account], s.TransactionReferenceNumber)
+		}
+	}
+	want := map[string][]string{"ACCOUNT1": {"FIRST", "THIRD"}, "ACCOUNT2": {"SECOND"}, "": {"FOURTH"}}
+	if !reflect.DeepEqual(accounts, []string{"ACCOUNT1", "ACCOUNT2", ""}) || !reflect.DeepEqual(grouped, want) {
+		t.Errorf("ByAccount() = %v %v, want %v", accounts, grouped, want)
+	}
+}
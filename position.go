@@ -0,0 +1,71 @@
+package mt940
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+type Position struct {
+	File   string
+	Line   int // 1-based
+	Column int // 1-based, in characters
+	Offset int // Byte offset of the start
+	End    int // Byte offset just past the end
+}
+
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	s := fmt.Sprintf("%d:%d", p.Line, p.Column)
+	if p.File != "" {
+		s = p.File + ":" + s
+	}
+	return s
+}
+
+// Byte offsets of the start of every line, used to translate offsets into
+// positions without rescanning the input
+type lineIndex struct {
+	file   string
+	data   []byte
+	starts []int
+}
+
+func newLineIndex(file string, data []byte) *lineIndex {
+	li := &lineIndex{file: file, data: data, starts: []int{0}}
+	for i, c := range data {
+		if c == '\n' {
+			li.starts = append(li.starts, i+1)
+		}
+	}
+	return li
+}
+
+func (li *lineIndex) position(start, end int) Position {
+	line := sort.Search(len(li.starts), func(i int) bool {
+		return li.starts[i] > start
+	})
+	return Position{
+		File:   li.file,
+		Line:   line,
+		Column: utf8.RuneCount(li.data[li.starts[line-1]:start]) + 1,
+		Offset: start,
+		End:    end,
+	}
+}
+
+// Text of a 1-based line without its line terminator
+func (li *lineIndex) line(n int) string {
+	if n < 1 || n > len(li.starts) {
+		return ""
+	}
+	end := len(li.data)
+	if n < len(li.starts) {
+		end = li.starts[n]
+	}
+	return strings.TrimRight(string(li.data[li.starts[n-1]:end]), "\r\n")
+}
@@ -0,0 +1,31 @@
+package mt940
+
+import (
+	"testing"
+)
+
+func TestLineIndex_Position(t *testing.T) {
+	li := newLineIndex("test.sta", []byte(":20:REF\r\n:25:ACCOUNT\n:86:détails\n:62F:C"))
+	tests := []struct {
+		start, end int
+		want       Position
+		line       string
+	}{
+		{0, 9, Position{"test.sta", 1, 1, 0, 9}, ":20:REF"},
+		{9, 21, Position{"test.sta", 2, 1, 9, 21}, ":25:ACCOUNT"},
+		{13, 21, Position{"test.sta", 2, 5, 13, 21}, ":25:ACCOUNT"},
+		{31, 32, Position{"test.sta", 3, 10, 31, 32}, ":86:détails"},
+		{34, 40, Position{"test.sta", 4, 1, 34, 40}, ":62F:C"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want.String(), func(t *testing.T) {
+			got := li.position(tt.start, tt.end)
+			if got != tt.want {
+				t.Errorf("position() got %v, want %v", got, tt.want)
+			}
+			if line := li.line(got.Line); line != tt.line {
+				t.Errorf("line() got %q, want %q", line, tt.line)
+			}
+		})
+	}
+}
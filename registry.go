@@ -0,0 +1,170 @@
+package mt940
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+var (
+	ErrTagExists        = NewParseError("tag is already registered")
+	ErrInvalidTagDef    = NewParseError("tag definition needs an id and a format, regexp or parse func")
+	ErrTagNotRegistered = NewParseError("tag is not registered")
+)
+
+// Definition of a tag for a Registry, the value following the tag marker is
+// handed to Parse when it is set, or matched against Regexp with its named
+// groups becoming the results, or else parsed with Format naming its fields
+// after Fields
+type TagDef struct {
+	ID       string // Two digits and an optional letter, eg. 60F, or NS
+	Name     string
+	Format   string   // SWIFT notation, eg. 16x or 3!a15d
+	Fields   []string // Names of the Format fields
+	Regexp   *regexp.Regexp
+	Parse    func(value string) (TagResults, error)
+	Examples []string
+}
+
+// Value of a registered tag the model has no field for, eg. a bank
+// proprietary tag
+type ExtraTag struct {
+	ID      string
+	Value   string // Tag text starting with :id:
+	Results TagResults
+}
+
+// Set of tags known to a parser, safe for concurrent use
+type Registry struct {
+	mu   sync.RWMutex
+	tags map[string]Tag
+}
+
+// Registry with the standard tags, used by parsers without their own
+var DefaultRegistry = newRegistry(Tags)
+
+func newRegistry(tags map[string]Tag) *Registry {
+	r := &Registry{tags: make(map[string]Tag, len(tags))}
+	for id, tag := range tags {
+		r.tags[id] = tag
+	}
+	return r
+}
+
+// Returns a registry with the standard tags that can be changed without
+// affecting DefaultRegistry
+func NewRegistry() *Registry {
+	return DefaultRegistry.Clone()
+}
+
+func (r *Registry) Clone() *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return newRegistry(r.tags)
+}
+
+// Adds a tag, failing with ErrTagExists if its id is already registered
+func (r *Registry) Register(def TagDef) error {
+	tag, err := def.tag()
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tags[def.ID]; ok {
+		return ErrTagExists
+	}
+	r.set(tag)
+	return nil
+}
+
+// Replaces a registered tag, failing with ErrTagNotRegistered if there is
+// nothing to replace
+func (r *Registry) Override(def TagDef) error {
+	tag, err := def.tag()
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tags[def.ID]; !ok {
+		return ErrTagNotRegistered
+	}
+	r.set(tag)
+	return nil
+}
+
+func (r *Registry) set(tag Tag) {
+	if r.tags == nil {
+		r.tags = map[string]Tag{}
+	}
+	r.tags[tag.id] = tag
+}
+
+func (r *Registry) Lookup(id string) (TagDef, bool) {
+	tag, ok := r.lookup(id)
+	if !ok {
+		return TagDef{}, false
+	}
+	return tag.Def(), true
+}
+
+func (r *Registry) lookup(id string) (Tag, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tag, ok := r.tags[id]
+	return tag, ok
+}
+
+func (r *Registry) known(id string) bool {
+	_, ok := r.lookup(id)
+	return ok
+}
+
+func (def TagDef) tag() (Tag, error) {
+	if def.ID == "" || def.Format == "" && def.Regexp == nil && def.Parse == nil {
+		return Tag{}, ErrInvalidTagDef
+	}
+	if !tagIDRegex.MatchString(def.ID) {
+		return Tag{}, fmt.Errorf("%w: id %v isn't two digits and an optional letter or NS", ErrInvalidTagDef, def.ID)
+	}
+	tag := Tag{
+		id:        def.ID,
+		name:      def.Name,
+		re:        def.Regexp,
+		parseFunc: def.Parse,
+		examples:  def.Examples,
+	}
+	if def.Format != "" {
+		format, err := CompileFormat(def.Format, def.Fields...)
+		if err != nil {
+			return Tag{}, err
+		}
+		tag.format = format
+		if tag.re == nil && tag.parseFunc == nil {
+			tag.parseFunc = format.Parse
+		}
+	}
+	// Field limits only hold for the standard definition of a tag
+	if std, ok := Tags[def.ID]; ok {
+		tag.status, tag.subre = std.status, std.subre
+		if def.Regexp == std.re && def.Parse == nil {
+			tag.limits = std.limits
+		}
+	}
+	return tag, nil
+}
+
+func (t *Tag) Def() TagDef {
+	def := TagDef{
+		ID:       t.id,
+		Name:     t.name,
+		Regexp:   t.re,
+		Parse:    t.parseFunc,
+		Examples: t.examples,
+	}
+	if t.format != nil {
+		def.Format, def.Fields = t.format.Spec, t.format.Fields()
+	}
+	return def
+}
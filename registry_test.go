@@ -0,0 +1,127 @@
+package mt940
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	tests := []struct {
+		name    string
+		def     TagDef
+		wantErr error
+	}{
+		{"new tag", TagDef{ID: "99", Regexp: regexp.MustCompile(`(?P<value>.*)`)}, nil},
+		{"parse func", TagDef{ID: "98", Parse: func(string) (TagResults, error) { return nil, nil }}, nil},
+		{"existing tag", TagDef{ID: "20", Regexp: regexp.MustCompile(`.*`)}, ErrTagExists},
+		{"no id", TagDef{Regexp: regexp.MustCompile(`.*`)}, ErrInvalidTagDef},
+		{"no regexp", TagDef{ID: "97"}, ErrInvalidTagDef},
+		{"three digits", TagDef{ID: "999", Regexp: regexp.MustCompile(`.*`)}, ErrInvalidTagDef},
+		{"suffixed NS", TagDef{ID: "NS1", Regexp: regexp.MustCompile(`.*`)}, ErrInvalidTagDef},
+		{"lower case", TagDef{ID: "99x", Regexp: regexp.MustCompile(`.*`)}, ErrInvalidTagDef},
+		{"format", TagDef{ID: "96", Format: "3!a15d", Fields: []string{"currency", "amount"}}, nil},
+		{"invalid format", TagDef{ID: "95", Format: "3!q"}, ErrInvalidFormat},
+	}
+	r := NewRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := r.Register(tt.def); !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("Register() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, ok := r.Lookup(tt.def.ID); ok != (tt.wantErr == nil || tt.wantErr == ErrTagExists) {
+				t.Errorf("Lookup(%q) ok = %v", tt.def.ID, ok)
+			}
+		})
+	}
+	if _, ok := DefaultRegistry.Lookup("99"); ok {
+		t.Error("Register() changed DefaultRegistry")
+	}
+
+	tag, _ := r.lookup("96")
+	got, err := tag.Parse(":96:EUR12,50")
+	if want := (TagResults{"currency": "EUR", "amount": "12,50"}); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Tag.Parse() = %v, %v, want %v", got, err, want)
+	}
+	if def, _ := r.Lookup("96"); def.Format != "3!a15d" || !reflect.DeepEqual(def.Fields, []string{"currency", "amount"}) {
+		t.Errorf("Lookup() = %v, want the format", def)
+	}
+}
+
+func TestRegistry_Override(t *testing.T) {
+	r := NewRegistry()
+	re := regexp.MustCompile(`(?P<transaction_reference>[A-Z]+)`)
+	if err := r.Override(TagDef{ID: "20", Name: "Reference", Regexp: re}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Override(TagDef{ID: "99", Regexp: re}); err != ErrTagNotRegistered {
+		t.Errorf("Override() error = %v, want %v", err, ErrTagNotRegistered)
+	}
+
+	def, _ := r.Lookup("20")
+	if def.Name != "Reference" || def.Regexp != re {
+		t.Errorf("Lookup() = %v, want the override", def)
+	}
+	if def, _ := DefaultRegistry.Lookup("20"); def.Regexp == re {
+		t.Error("Override() changed DefaultRegistry")
+	}
+}
+
+func TestTransactions_Parse_Registry(t *testing.T) {
+	input := ":20:REF\n:60F:C171011HUF1,00\n:99:ABC/123\n:62F:C171011HUF1,00\n"
+
+	if _, err := (&Transactions{}).Parse(strings.NewReader(input)); !errors.Is(err, ErrNotExist) {
+		t.Fatalf("Transactions.Parse() error = %v, want %v", err, ErrNotExist)
+	}
+
+	proprietary := NewRegistry()
+	err := proprietary.Register(TagDef{
+		ID:   "99",
+		Name: "BankProprietary",
+		Parse: func(value string) (TagResults, error) {
+			parts := strings.SplitN(value, "/", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("missing /")
+			}
+			return TagResults{"code": parts[0], "number": parts[1]}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	strict := NewRegistry()
+	err = strict.Override(TagDef{ID: "20", Regexp: regexp.MustCompile(`^(?P<transaction_reference>[0-9]+)$`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both dialects are used at the same time
+	var wg sync.WaitGroup
+	var got []Transaction
+	var strictErr ParseError
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		got, err = (&Transactions{Registry: proprietary}).Parse(strings.NewReader(input))
+	}()
+	go func() {
+		defer wg.Done()
+		_, strictErr = (&Transactions{Registry: strict}).Parse(strings.NewReader(input))
+	}()
+	wg.Wait()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ExtraTag{{"99", ":99:ABC/123", TagResults{"code": "ABC", "number": "123"}}}
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Extra, want) {
+		t.Errorf("Transactions.Parse() Extra = %v, want %v", got, want)
+	}
+	if !errors.Is(strictErr, ErrTagDidNotParse) {
+		t.Errorf("Transactions.Parse() with overridden :20: error = %v, want %v", strictErr, ErrTagDidNotParse)
+	}
+}
@@ -0,0 +1,173 @@
+package mt940
+
+import (
+	"fmt"
+
+	"golang.org/x/text/currency"
+)
+
+// Rules reported in the ValidationErrors of the network validation, named
+// after the SWIFT error codes where there is one
+const (
+	RuleCurrencyCode    = "C27"              // Balance currencies start with the same two characters (rule C2)
+	RuleDecimals        = "C03"              // Amounts have at most the minor units of their currency
+	RuleFundsCode       = "funds-code"       // The :61: funds code is the third character of the currency
+	RuleEntrySums       = "entry-sums"       // :90D: and :90C: match the entries
+	RuleFloorLimit      = "floor-limit"      // Entries of an interim report reach the :34F: floor limit
+	RuleAccountCurrency = "account-currency" // The currency suffix of :25: is the one of the opening balance
+)
+
+type taggedBalance struct {
+	id  string
+	pos Position
+	*Balance
+}
+
+// Balances of the statement by tag id in the order the rules check them
+func (tr *Transaction) balances() []taggedBalance {
+	balances := []taggedBalance{
+		{"60F", tr.Positions["60F"], &tr.FinalOpeningBalance},
+		{"60M", tr.Positions["60M"], &tr.IntermediateOpeningBalance},
+		{"62M", tr.Positions["62M"], &tr.IntermediateClosingBalance},
+		{"62F", tr.Positions["62F"], &tr.FinalClosingBalance},
+		{"64", tr.Positions["64"], &tr.AvailableBalance},
+	}
+	for i := range tr.ForwardAvailableBalance {
+		b := &tr.ForwardAvailableBalance[i]
+		balances = append(balances, taggedBalance{"65", b.pos, b})
+	}
+	return balances
+}
+
+// Checks the SWIFT network validated rules which apply to the values of the
+// statement, the errors hold the position of the offending tag
+func (tr *Transaction) CheckNetworkRules() []*TagError {
+	var errs []*TagError
+	report := func(pos Position, rule, format string, args ...interface{}) {
+		errs = append(errs, &TagError{ParseError: &ValidationError{rule, fmt.Sprintf(format, args...)}, Pos: pos})
+	}
+
+	code, codeID := "", ""
+	for _, b := range tr.balances() {
+		if b.code == "" {
+			continue
+		}
+		pos := b.pos
+		switch {
+		case code == "":
+			code, codeID = b.code, b.id
+		case len(b.code) < 2 || b.code[:2] != code[:2]:
+			report(pos, RuleCurrencyCode, "currency %v of :%v: doesn't match %v of :%v:", b.code, b.id, code, codeID)
+		}
+		checkDecimals(b.code, b.decimals, func(format string, args ...interface{}) {
+			report(pos, RuleDecimals, ":%v: "+format, append([]interface{}{b.id}, args...)...)
+		})
+	}
+
+	for i, e := range tr.Entries {
+		pos := e.Positions["61"]
+		switch {
+		case e.FundsCode == "":
+		case code == "":
+			report(pos, RuleFundsCode, "funds code %v of entry %v has no balance currency to match", e.FundsCode, i+1)
+		case len(code) < 3 || e.FundsCode != code[2:3]:
+			report(pos, RuleFundsCode, "funds code %v of entry %v isn't the third character of %v", e.FundsCode, i+1, code)
+		}
+		checkDecimals(code, e.decimals, func(format string, args ...interface{}) {
+			report(pos, RuleDecimals, "entry %v "+format, append([]interface{}{i + 1}, args...)...)
+		})
+	}
+	return errs
+}
+
+// Amounts in unknown currencies or without one are checked against the
+// decimals an Amount keeps
+func checkDecimals(code string, decimals int, report func(format string, args ...interface{})) {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		if decimals > amountDecimals {
+			report("has %v decimals, amounts keep %v", decimals, amountDecimals)
+		}
+		return
+	}
+	if scale, _ := currency.Standard.Rounding(unit); decimals > scale {
+		report("has %v decimals, %v allows %v", decimals, code, scale)
+	}
+}
+
+// Checks that the number and sum of entries in :90D: and :90C: match the
+// :61: entries of the statement
+func (tr *Transaction) CheckEntrySummaries() []*TagError {
+	var errs []*TagError
+	for _, s := range []struct {
+		id    string
+		debit bool
+		*EntrySummary
+	}{{"90D", true, tr.DebitEntries}, {"90C", false, tr.CreditEntries}} {
+		if s.EntrySummary == nil {
+			continue
+		}
+		var count int
+		var sum int64
+		for i := range tr.Entries {
+			if e := &tr.Entries[i]; e.isDebit() == s.debit {
+				count++
+				sum += e.int64
+			}
+		}
+		if count != s.Count || sum != s.int64 {
+			errs = append(errs, &TagError{
+				ParseError: &ValidationError{RuleEntrySums, fmt.Sprintf(
+					":%v: has %v entries of %v, the statement has %v of %v",
+					s.id, s.Count, s.Decimal(), count, Amount{sum}.Decimal())},
+				Pos: tr.Positions[s.id],
+			})
+		}
+	}
+	return errs
+}
+
+// Reports the entries below the floor limit of the statement, which the bank
+// shouldn't have sent
+func (tr *Transaction) CheckFloorLimit() []*TagError {
+	if tr.FloorLimit == nil {
+		return nil
+	}
+	var errs []*TagError
+	for i := range tr.Entries {
+		e := &tr.Entries[i]
+		limit := tr.FloorLimit.Credit
+		if e.isDebit() {
+			limit = tr.FloorLimit.Debit
+		}
+		if e.int64 < limit.int64 {
+			errs = append(errs, &TagError{
+				ParseError: &ValidationError{RuleFloorLimit, fmt.Sprintf(
+					"entry %v of %v is below the floor limit of %v", i+1, e.Decimal(), limit.Decimal())},
+				Pos: e.Positions["61"],
+			})
+		}
+	}
+	return errs
+}
+
+// Reports a currency suffix of the :25: account that differs from the
+// currency of the opening balance
+func (tr *Transaction) CheckAccountCurrency() []*TagError {
+	account := tr.Account()
+	if !account.HasCurrency() {
+		return nil
+	}
+	id, b := "60F", &tr.FinalOpeningBalance
+	if b.code == "" {
+		id, b = "60M", &tr.IntermediateOpeningBalance
+	}
+	if b.code == "" || b.code == account.Currency.String() {
+		return nil
+	}
+	return []*TagError{{
+		ParseError: &ValidationError{RuleAccountCurrency, fmt.Sprintf(
+			"currency suffix %v of account %v doesn't match %v of :%v:", account.Currency, tr.AccountIdentification, b.code, id)},
+		Pos: tr.Positions["25"],
+	}}
+}
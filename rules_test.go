@@ -0,0 +1,234 @@
+package mt940
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/currency"
+)
+
+func TestTransaction_CheckNetworkRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string // Rule and line of each violation
+	}{
+		{
+			"valid",
+			":20:REF\n:60F:C171011EUR1,00\n:61:171011CR2,50NTRFNONREF\n:62F:C171011EUR3,50\n:64:C171011EUR3,5\n",
+			nil,
+		},
+		{
+			"currency prefix",
+			":20:REF\n:60F:C171011EUR1,00\n:62F:C171011USD1,00\n:64:C171011EUR1,00\n",
+			[]string{"C27:3"},
+		},
+		{
+			"decimals",
+			":20:REF\n:60F:C171011JPY100,\n:61:171011C2,5NTRFNONREF\n:62F:C171011JPY102,50\n",
+			[]string{"C03:4", "C03:3"},
+		},
+		{
+			"three decimals",
+			":20:REF\n:60F:C171011BHD1,125\n:62F:C171011BHD1,125\n",
+			nil,
+		},
+		{
+			"funds code",
+			":20:REF\n:60F:C171011EUR1,00\n:61:171011CR2,50NTRFNONREF\n:61:171011CD2,50NTRFNONREF\n:62F:C171011EUR1,00\n",
+			[]string{"funds-code:4"},
+		},
+		{
+			"forward available balance",
+			":20:REF\n:60F:C171011EUR1,00\n:62F:C171011EUR1,00\n:65:C171012EUR1,00\n:65:C171013USD1,00\n",
+			[]string{"C27:5"},
+		},
+		{
+			"no currency",
+			":20:REF\n:61:171011CR2,5001NTRFNONREF\n:61:171011C2,50NTRFNONREF\n",
+			[]string{"funds-code:2", "C03:2"},
+		},
+		{
+			"unknown currency",
+			":20:REF\n:60F:C171011XYZ1,123\n:61:171011CZ2,50NTRFNONREF\n:62F:C171011XYZ1,00\n",
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&Transactions{Lenient: true}).Parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var rules []string
+			for _, te := range got[0].CheckNetworkRules() {
+				var ve *ValidationError
+				if !errors.As(te, &ve) {
+					t.Fatalf("CheckNetworkRules() error %v isn't a ValidationError", te)
+				}
+				rules = append(rules, ve.Rule+":"+strconv.Itoa(te.Pos.Line))
+			}
+			if !reflect.DeepEqual(rules, tt.want) {
+				t.Errorf("CheckNetworkRules() = %v, want %v", rules, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransactions_Parse_NetworkRules(t *testing.T) {
+	input := ":20:REF\n:60F:C171011EUR1,00\n:62F:C171011USD1,00\n"
+
+	_, err := (&Transactions{NetworkRules: true}).Parse(strings.NewReader(input))
+	var te *TagError
+	if !errors.As(err, &te) || !errors.Is(err, ErrValidation) || te.Source != ":62F:C171011USD1,00" {
+		t.Errorf("Transactions.Parse() error = %v, want a validation error on :62F:", err)
+	}
+
+	tr := &Transactions{NetworkRules: true, Lenient: true}
+	if got, err := tr.Parse(strings.NewReader(input)); err != nil || len(got) != 1 || len(tr.Diagnostics) != 1 {
+		t.Errorf("Transactions.Parse() = %v, %v, diagnostics %v, want 1 statement and 1 diagnostic", got, err, tr.Diagnostics)
+	}
+}
+
+func TestTransaction_CheckNetworkRules_Fixture(t *testing.T) {
+	got, err := (&Transactions{AutoDetectEncoding: true}).Parse(bytes.NewReader(must(os.ReadFile("self-provided/raiffeisen-cmi.sta"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := &got[0]
+	if len(tr.ForwardAvailableBalance) != 3 {
+		t.Fatalf("ForwardAvailableBalance = %v, want the 3 :65: balances", tr.ForwardAvailableBalance)
+	}
+	if b := tr.ForwardAvailableBalance[2]; !b.Timestamp.Time.Equal(time.Date(2018, 4, 20, 0, 0, 0, 0, time.UTC)) || b.Amount != NewAmount(2528168760) {
+		t.Errorf("ForwardAvailableBalance[2] = %v %v", b.Timestamp, b.Decimal())
+	}
+	if errs := tr.CheckNetworkRules(); len(errs) != 0 {
+		t.Errorf("CheckNetworkRules() = %v, want none", errs)
+	}
+}
+
+func TestTransaction_CheckEntrySummaries(t *testing.T) {
+	tests := []struct {
+		file   string
+		debit  EntrySummary
+		credit *EntrySummary
+		want   []string
+	}{
+		{"mBank/mt942.sta", EntrySummary{0, currency.PLN, NewAmount(0)}, &EntrySummary{3, currency.PLN, NewAmount(3)}, nil},
+		{"self-provided/mt942.sta", EntrySummary{1, currency.EUR, NewAmount(230)}, nil, []string{
+			"entry-sums: :90D: has 1 entries of 2,30, the statement has 1 of 0,42",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			tr := &Transactions{}
+			got, err := tr.Parse(bytes.NewReader(must(os.ReadFile(tt.file))))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got[0].DebitEntries == nil || *got[0].DebitEntries != tt.debit {
+				t.Errorf("DebitEntries = %v, want %v", got[0].DebitEntries, tt.debit)
+			}
+			if !reflect.DeepEqual(got[0].CreditEntries, tt.credit) {
+				t.Errorf("CreditEntries = %v, want %v", got[0].CreditEntries, tt.credit)
+			}
+			var msgs []string
+			for _, te := range got[0].CheckEntrySummaries() {
+				msgs = append(msgs, te.ParseError.Error())
+				if te.Pos.Line != 11 {
+					t.Errorf("CheckEntrySummaries() position = %v, want the :90D: line", te.Pos)
+				}
+			}
+			if !reflect.DeepEqual(msgs, tt.want) {
+				t.Errorf("CheckEntrySummaries() = %q, want %q", msgs, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransaction_CheckFloorLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  *FloorLimit
+		below []int // Lines of the entries below the floor limit
+	}{
+		{"none", ":20:REF\n:61:1610301031D0,42NMSCNONREF\n", nil, nil},
+		{
+			"both",
+			":20:REF\n:34F:PLN1,00\n:61:1701190119CN0,01NTRFNONREF\n:61:1701190119DN1,00NTRFNONREF\n",
+			&FloorLimit{NewAmount(100), NewAmount(100), currency.PLN}, []int{3},
+		},
+		{
+			"debit and credit",
+			":20:REF\n:34F:EURD0,50\n:34F:EURC2,00\n:61:1610301031D0,42NMSCNONREF\n:61:1610301031C1,42NMSCNONREF\n:61:1610301031RD1,42NMSCNONREF\n",
+			&FloorLimit{NewAmount(50), NewAmount(200), currency.EUR}, []int{4, 5, 6},
+		},
+		{
+			"self-provided", string(must(os.ReadFile("self-provided/mt942.sta"))),
+			&FloorLimit{NewAmount(0), NewAmount(0), currency.EUR}, nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := &Transactions{}
+			got, err := tr.Parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got[0].FloorLimit, tt.want) {
+				t.Errorf("FloorLimit = %v, want %v", got[0].FloorLimit, tt.want)
+			}
+			var below []int
+			for _, te := range got[0].CheckFloorLimit() {
+				below = append(below, te.Pos.Line)
+			}
+			if !reflect.DeepEqual(below, tt.below) {
+				t.Errorf("CheckFloorLimit() on lines %v, want %v", below, tt.below)
+			}
+		})
+	}
+}
+
+func TestTransaction_CheckAccountCurrency(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"matching", ":20:REF\n:25:NL08DEUT0319809633EUR\n:60F:C171011EUR1,00\n", nil},
+		{"no suffix", ":20:REF\n:25:NL08DEUT0319809633\n:60F:C171011USD1,00\n", nil},
+		{
+			"different", ":20:REF\n:25:UBRTHUHB/123456789150ABCDEF002/HUF\n:60F:C171011EUR1,00\n",
+			[]string{"account-currency: currency suffix HUF of account UBRTHUHB/123456789150ABCDEF002/HUF doesn't match EUR of :60F:"},
+		},
+		{
+			"page", ":20:REF\n:25:NL08DEUT0319809633EUR\n:28C:1/2\n:60M:C171011USD1,00\n",
+			[]string{"account-currency: currency suffix EUR of account NL08DEUT0319809633EUR doesn't match USD of :60M:"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&Transactions{}).Parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var msgs []string
+			for _, te := range got[0].CheckAccountCurrency() {
+				msgs = append(msgs, te.ParseError.Error())
+				if te.Pos.Line != 2 {
+					t.Errorf("CheckAccountCurrency() position = %v, want the :25: line", te.Pos)
+				}
+			}
+			if !reflect.DeepEqual(msgs, tt.want) {
+				t.Errorf("CheckAccountCurrency() = %q, want %q", msgs, tt.want)
+			}
+		})
+	}
+}
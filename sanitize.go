@@ -0,0 +1,83 @@
+package mt940
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrBinaryInput = NewParseError("too many invalid bytes in input")
+
+type Replacement struct {
+	Offset   int    // Byte offset in the transcoded input
+	Original []byte // Control character or invalid UTF-8 byte
+}
+
+// U+FFFD counts as invalid too, it shows the input went through a lossy
+// conversion before
+func isInvalidRune(r rune) bool {
+	switch {
+	case r == utf8.RuneError:
+		return true
+	case r == '\r' || r == '\n':
+		return false
+	}
+	return unicode.IsControl(r)
+}
+
+// Replaces control characters other than CR and LF and invalid UTF-8 with
+// replacement, or strips them when replacement is 0
+func sanitize(data []byte, replacement rune) ([]byte, []Replacement) {
+	var replacements []Replacement
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if !isInvalidRune(r) {
+			out = append(out, data[i:i+size]...)
+		} else {
+			replacements = append(replacements, Replacement{i, data[i : i+size]})
+			if replacement != 0 {
+				out = utf8.AppendRune(out, replacement)
+			}
+		}
+		i += size
+	}
+	return out, replacements
+}
+
+func checkInvalidRatio(replacements []Replacement, size int, max float64) ParseError {
+	invalid := 0
+	for _, r := range replacements {
+		invalid += len(r.Original)
+	}
+	if max > 0 && size > 0 && float64(invalid)/float64(size) > max {
+		return &SyntaxError{fmt.Errorf("%w: %d of %d bytes", ErrBinaryInput, invalid, size)}
+	}
+	return nil
+}
+
+// Maps each byte offset of the sanitized input and its end to the offset in
+// the size bytes it was sanitized from. Stripped bytes go with the character
+// after them
+func sanitizeOffsets(size int, replacements []Replacement, replacement rune) []int {
+	offsets := make([]int, 0, size+1)
+	in, from := 0, 0
+	for _, r := range replacements {
+		for ; in < r.Offset; in++ {
+			offsets = append(offsets, from)
+			from = in + 1
+		}
+		if replacement != 0 {
+			for j := 0; j < utf8.RuneLen(replacement); j++ {
+				offsets = append(offsets, from)
+			}
+			from = r.Offset + len(r.Original)
+		}
+		in += len(r.Original)
+	}
+	for ; in < size; in++ {
+		offsets = append(offsets, from)
+		from = in + 1
+	}
+	return append(offsets, size)
+}
@@ -0,0 +1,83 @@
+package mt940
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		replacement rune
+		want        string
+		offsets     []int
+	}{
+		{"clean", ":20:REF\r\n:86:Grüße\n", ' ', ":20:REF\r\n:86:Grüße\n", nil},
+		{"replace", "\x01\n:20:R\x00EF\n-\x03", ' ', " \n:20:R EF\n- ", []int{0, 7, 12}},
+		{"strip", ":86:a\tb\x7fc\x85d", 0, ":86:abcd", []int{5, 7, 9}},
+		{"invalid utf-8", ":86:Illet\xe9k \xef\xbf\xbd", '?', ":86:Illet?k ?", []int{9, 12}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, replacements := sanitize([]byte(tt.input), tt.replacement)
+			if string(got) != tt.want {
+				t.Errorf("sanitize() got %q, want %q", got, tt.want)
+			}
+			var offsets []int
+			for _, r := range replacements {
+				offsets = append(offsets, r.Offset)
+			}
+			if !reflect.DeepEqual(offsets, tt.offsets) {
+				t.Errorf("sanitize() offsets %v, want %v", offsets, tt.offsets)
+			}
+		})
+	}
+}
+
+func TestSanitize_Fixtures(t *testing.T) {
+	// The U+FFFD of bytes lost before the file was stored as UTF-8
+	_, replacements := sanitize(must(os.ReadFile("sberbank/171011_01234945.sta")), '?')
+	var offsets []int
+	for _, r := range replacements {
+		offsets = append(offsets, r.Offset)
+	}
+	if want := []int{253, 263, 484, 696}; !reflect.DeepEqual(offsets, want) {
+		t.Errorf("sanitize() offsets %v, want %v", offsets, want)
+	}
+
+	// The binary characters are UTF-8 letters, which are kept
+	tr := &Transactions{Sanitize: true}
+	got, err := tr.Parse(must(os.Open("betterplace/with_binary_character.sta")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.Replacements) != 0 || len(got) != 2 || got[0].TransactionReferenceNumber != "STAR1ÜTßUMS" {
+		t.Errorf("Transactions.Parse() = %v statements, replacements %v", len(got), tr.Replacements)
+	}
+}
+
+func TestTransactions_Parse_Sanitize(t *testing.T) {
+	input := "\x01\n:20:REF\n:60F:C170119PLN0,40\n:86:TRANS\x00AKCJA\n:62F:C170119PLN0,43\n-\x03"
+
+	tr := &Transactions{Sanitize: true}
+	got, err := tr.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].HeaderInformation != "TRANSAKCJA" || len(tr.Replacements) != 3 {
+		t.Errorf("Transactions.Parse() = %q, replacements %v", got[0].HeaderInformation, tr.Replacements)
+	}
+
+	_, err = (&Transactions{Sanitize: true, MaxInvalidRatio: 0.01}).Parse(strings.NewReader(input))
+	if !errors.Is(err, ErrBinaryInput) {
+		t.Errorf("Transactions.Parse() error = %v, want %v", err, ErrBinaryInput)
+	}
+	_, err = (&Transactions{Sanitize: true, MaxInvalidRatio: 0.1}).Parse(strings.NewReader(input))
+	if err != nil {
+		t.Errorf("Transactions.Parse() error = %v", err)
+	}
+}
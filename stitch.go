@@ -0,0 +1,137 @@
+package mt940
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+var (
+	ErrMissingPage     = NewParseError("statement page missing")
+	ErrDuplicatePage   = NewParseError("statement page repeated")
+	ErrBalanceMismatch = NewParseError("intermediate balances don't match")
+)
+
+type pageKey struct {
+	account, number string
+}
+
+// Joins the pages of statements split by their :28C: sequence number into
+// one statement each, in place of their first page. The pages of a statement
+// share the account and statement number, their sequence numbers run from 1
+// without gaps and each :62M: is carried forward by the :60M: of the next
+// page. Statements without a sequence number are kept as they are, so are
+// the pages of statements that can't be stitched, which are reported in the
+// returned Errors
+func Stitch(pages []Transaction) ([]Transaction, error) {
+	var keys []pageKey
+	groups := make(map[pageKey][]Transaction)
+	// The pages kept as they are and the first page of each statement, key
+	// indexes keys or is -1 for a kept page
+	type slot struct{ page, key int }
+	var order []slot
+	for i, page := range pages {
+		if page.StatementSeqNumber == "" {
+			order = append(order, slot{i, -1})
+			continue
+		}
+		key := pageKey{page.AccountIdentification, page.StatementNumber}
+		if _, ok := groups[key]; !ok {
+			order = append(order, slot{i, len(keys)})
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], page)
+	}
+
+	var stitched []Transaction
+	var errs Errors
+	for _, s := range order {
+		if s.key < 0 {
+			stitched = append(stitched, pages[s.page])
+			continue
+		}
+		key := keys[s.key]
+		tr, err := stitch(key, groups[key])
+		if err != nil {
+			errs = append(errs, WrapParseError(err))
+			stitched = append(stitched, groups[key]...)
+			continue
+		}
+		stitched = append(stitched, tr)
+	}
+	return stitched, errs.Err()
+}
+
+func stitch(key pageKey, pages []Transaction) (Transaction, error) {
+	pages = append([]Transaction(nil), pages...)
+	seq := make([]int, len(pages))
+	for i := range pages {
+		n, err := strconv.Atoi(pages[i].StatementSeqNumber)
+		if err != nil {
+			return Transaction{}, fmt.Errorf("statement %v of account %v: page without a sequence number: %w", key.number, key.account, err)
+		}
+		seq[i] = n
+	}
+	sort.Stable(byPage{pages, seq})
+
+	for i := range pages {
+		if i > 0 && seq[i] == seq[i-1] {
+			return Transaction{}, fmt.Errorf("%w: page %v of statement %v of account %v", ErrDuplicatePage, seq[i], key.number, key.account)
+		}
+		if seq[i] != i+1 {
+			return Transaction{}, fmt.Errorf("%w: page %v of statement %v of account %v", ErrMissingPage, i+1, key.number, key.account)
+		}
+	}
+	last := &pages[len(pages)-1]
+	if last.FinalClosingBalance.Timestamp.Time == nil && last.IntermediateClosingBalance.Timestamp.Time != nil {
+		return Transaction{}, fmt.Errorf("%w: page %v of statement %v of account %v", ErrMissingPage, len(pages)+1, key.number, key.account)
+	}
+	for i := 1; i < len(pages); i++ {
+		closing, opening := &pages[i-1].IntermediateClosingBalance, &pages[i].IntermediateOpeningBalance
+		if closing.Status != opening.Status || closing.Amount != opening.Amount || balanceCode(closing) != balanceCode(opening) {
+			return Transaction{}, fmt.Errorf("%w: :62M: %v%v %v of page %v, :60M: %v%v %v of page %v of statement %v of account %v",
+				ErrBalanceMismatch, closing.Status, balanceCode(closing), closing.Decimal(), i,
+				opening.Status, balanceCode(opening), opening.Decimal(), i+1, key.number, key.account)
+		}
+	}
+
+	tr := pages[0]
+	tr.Entries = append([]Entry(nil), tr.Entries...)
+	tr.NonSwift = NonSwift{}.add(tr.NonSwift)
+	tr.Extra = append([]ExtraTag(nil), tr.Extra...)
+	tr.Unknown = append([]ExtraTag(nil), tr.Unknown...)
+	for i := range pages[1:] {
+		page := &pages[i+1]
+		tr.Entries = append(tr.Entries, page.Entries...)
+		tr.NonSwift.add(page.NonSwift)
+		tr.Extra = append(tr.Extra, page.Extra...)
+		tr.Unknown = append(tr.Unknown, page.Unknown...)
+	}
+	if len(tr.NonSwift) == 0 {
+		tr.NonSwift = nil
+	}
+	tr.IntermediateClosingBalance = Balance{}
+	tr.FinalClosingBalance = last.FinalClosingBalance
+	tr.AvailableBalance = last.AvailableBalance
+	tr.ForwardAvailableBalance = last.ForwardAvailableBalance
+	tr.DebitEntries, tr.CreditEntries = last.DebitEntries, last.CreditEntries
+	tr.Information = last.Information
+	tr.StatementLine, tr.TransactionDetails = StatementLine{}, ""
+	if e := tr.currentEntry(); e != nil {
+		tr.StatementLine, tr.TransactionDetails = e.StatementLine, e.TransactionDetails
+	}
+	return tr, nil
+}
+
+// Sorts pages by their sequence numbers
+type byPage struct {
+	pages []Transaction
+	seq   []int
+}
+
+func (p byPage) Len() int           { return len(p.pages) }
+func (p byPage) Less(i, j int) bool { return p.seq[i] < p.seq[j] }
+func (p byPage) Swap(i, j int) {
+	p.pages[i], p.pages[j] = p.pages[j], p.pages[i]
+	p.seq[i], p.seq[j] = p.seq[j], p.seq[i]
+}
@@ -0,0 +1,99 @@
+package mt940
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+const stitchPage1 = ":20:P1\n:25:NL08DEUT0319809633\n:28C:355/1\n:60F:C171011EUR10,00\n" +
+	":61:171011D2,50NTRFNONREF\n:86:first\n:62M:C171011EUR7,50\n-\n"
+
+const stitchPage2 = ":20:P2\n:25:NL08DEUT0319809633\n:28C:355/2\n:60M:C171011EUR7,50\n" +
+	":61:171011C1,00NTRFNONREF\n:86:second\n:62F:C171011EUR8,50\n:86:closing\n-\n"
+
+const stitchOther = ":20:OTHER\n:25:DK0230003617012345\n:28C:12/1\n:60F:C171011EUR1,00\n:62F:C171011EUR1,00\n-\n"
+
+func parseStitchInput(t *testing.T, input string) []Transaction {
+	t.Helper()
+	got, err := (&Transactions{}).Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestStitch(t *testing.T) {
+	pages := parseStitchInput(t, stitchPage2+stitchOther+stitchPage1)
+	if pages[0].AccountIdentification != "NL08DEUT0319809633" || pages[0].StatementNumber != "355" || pages[0].StatementSeqNumber != "2" {
+		t.Fatalf("page header = %v %v/%v", pages[0].AccountIdentification, pages[0].StatementNumber, pages[0].StatementSeqNumber)
+	}
+
+	got, err := Stitch(pages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Stitch() returned %v statements, want 2", len(got))
+	}
+	tr := got[0]
+	if tr.TransactionReferenceNumber != "P1" || len(tr.Entries) != 2 {
+		t.Fatalf("Stitch()[0] = %v with %v entries, want P1 with 2", tr.TransactionReferenceNumber, len(tr.Entries))
+	}
+	if tr.Entries[0].TransactionDetails != "first" || tr.Entries[1].TransactionDetails != "second" {
+		t.Errorf("entries = %q, %q, want first and second", tr.Entries[0].TransactionDetails, tr.Entries[1].TransactionDetails)
+	}
+	if tr.FinalOpeningBalance.Amount != NewAmount(1000) || tr.FinalClosingBalance.Amount != NewAmount(850) || tr.Information != "closing" {
+		t.Errorf("balances = %v to %v, information %q", tr.FinalOpeningBalance.Decimal(), tr.FinalClosingBalance.Decimal(), tr.Information)
+	}
+	if tr.TransactionDetails != "second" || tr.IntermediateClosingBalance.Timestamp.Time != nil {
+		t.Errorf("Stitch() keeps the details %q and :62M: %v of the pages", tr.TransactionDetails, tr.IntermediateClosingBalance)
+	}
+	if got[1].TransactionReferenceNumber != "OTHER" {
+		t.Errorf("Stitch()[1] = %v, want OTHER", got[1].TransactionReferenceNumber)
+	}
+	if len(pages[0].Entries) != 1 {
+		t.Errorf("Stitch() changed the entries of its pages")
+	}
+}
+
+func TestStitch_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{"missing first", stitchPage2, ErrMissingPage},
+		{"missing last", stitchPage1, ErrMissingPage},
+		{"missing middle", stitchPage1 + strings.Replace(stitchPage2, "355/2", "355/3", 1), ErrMissingPage},
+		{"repeated", stitchPage1 + stitchPage1 + stitchPage2, ErrDuplicatePage},
+		{"balance", stitchPage1 + strings.Replace(stitchPage2, ":60M:C171011EUR7,50", ":60M:C171011EUR7,60", 1), ErrBalanceMismatch},
+		{"currency", stitchPage1 + strings.Replace(stitchPage2, ":60M:C171011EUR", ":60M:C171011USD", 1), ErrBalanceMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := parseStitchInput(t, tt.input+stitchOther)
+			got, err := Stitch(pages)
+			var pe ParseError
+			if !errors.Is(err, tt.err) || !errors.As(err, &pe) {
+				t.Errorf("Stitch() error = %v, want %v", err, tt.err)
+			}
+			// The pages of the statement are kept, the others are still stitched
+			if len(got) != len(pages) || got[len(got)-1].TransactionReferenceNumber != "OTHER" {
+				t.Errorf("Stitch() returned %v statements, want the %v pages", len(got), len(pages))
+			}
+		})
+	}
+}
+
+func TestStitch_WithoutSequenceNumber(t *testing.T) {
+	pages := parseStitchInput(t, string(must(os.ReadFile("jejik/rabobank-iban.sta"))))
+	if len(pages) != 2 || pages[0].StatementNumber != pages[1].StatementNumber {
+		t.Fatalf("Transactions.Parse() = %v statements, want 2 with the same number", len(pages))
+	}
+	got, err := Stitch(pages)
+	if err != nil || len(got) != 2 {
+		t.Errorf("Stitch() = %v statements, %v, want both as they are", len(got), err)
+	}
+}
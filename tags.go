@@ -4,27 +4,52 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type Tag struct {
-	id       string
-	re       *regexp.Regexp
-	subre    *regexp.Regexp
-	name     string
-	status   string
-	examples []string
+	id        string
+	re        *regexp.Regexp
+	subre     *regexp.Regexp
+	name      string
+	format    *Format // SWIFT notation of the value, used for writing
+	parseFunc func(value string) (TagResults, error)
+	status    string
+	examples  []string
+	limits    map[string]fieldLimit
+}
+
+type fieldLimit struct {
+	length   int // Maximum characters per line
+	lines    int // Maximum number of lines, 0 for single line fields
+	decimals int // Maximum significant decimals of amounts, 0 for other fields
 }
 
 type TagError struct {
 	ParseError
 	*Tag
-	Value string
+	Value  string
+	Pos    Position
+	Source string // Line of the input the error occurred on
 }
 
 func (te *TagError) Error() string {
-	return fmt.Sprintf(
+	msg := fmt.Sprintf(
 		"tag parsing error: %v on tag %v value %v",
 		te.ParseError.Error(), te.Tag, te.Value)
+	if !te.Pos.IsValid() {
+		return msg
+	}
+	msg = te.Pos.String() + ": " + msg
+	if te.Source != "" {
+		msg += "\n" + te.Source + "\n" + strings.Repeat(" ", te.Pos.Column-1) + "^"
+	}
+	return msg
+}
+
+func (te *TagError) Unwrap() error {
+	return te.ParseError
 }
 
 type TagResults map[string]string
@@ -38,15 +63,29 @@ var (
 )
 
 var (
-	balanceRegexp = regexp.MustCompile(`(?P<status>[DC])(?P<year>[0-9]{2})(?P<month>[0-9]{2})(?P<day>[0-9]{2})(?P<currency>.{3})(?P<amount>[0-9,]{0,16})`)
+	balanceRegexp = regexp.MustCompile(`^(?P<status>[DC])(?P<year>[0-9]{2})(?P<month>[0-9]{2})(?P<day>[0-9]{2})(?P<currency>.{3})(?P<amount>[0-9,]+)$`)
+	sumRegexp     = regexp.MustCompile(`^(?P<number>[0-9]*)(?P<currency>.{3})(?P<amount>[0-9,]+)$`)
 	tagRegex      = regexp.MustCompile(`(?m)^:\n?(?P<full_tag>(?P<tag>[0-9]{2}|NS)(?P<sub_tag>[A-Z])?):`)
+	balanceFormat = MustCompileFormat("1!a2!n2!n2!n3!a15d", "status", "year", "month", "day", "currency", "amount")
+	sumFormat     = MustCompileFormat("5n3!a15d", "number", "currency", "amount")
+	balanceLimits = map[string]fieldLimit{
+		"amount": {length: 15, decimals: amountDecimals},
+	}
+	sumLimits = map[string]fieldLimit{
+		"number": {length: 5},
+		"amount": {length: 15, decimals: amountDecimals},
+	}
 )
 
 var Tags = map[string]Tag{
 	"20": Tag{
-		name: "TransactionReferenceNumber",
-		id:   "20",
-		re:   regexp.MustCompile(`(?P<transaction_reference>.{0,16})`),
+		name:   "TransactionReferenceNumber",
+		id:     "20",
+		re:     regexp.MustCompile(`(?P<transaction_reference>.*)`),
+		format: MustCompileFormat("16x", "transaction_reference"),
+		limits: map[string]fieldLimit{
+			"transaction_reference": {length: 16},
+		},
 		examples: []string{
 			":20:0000000030210056",
 		},
@@ -57,9 +96,13 @@ var Tags = map[string]Tag{
 		re:   regexp.MustCompile(`^(?P<year>[0-9]{2})(?P<month>[0-9]{2})(?P<day>[0-9]{2})(?P<hour>[0-9]{2})(?P<minute>[0-9]{2})(\+(?P<offset>[0-9]{4})|)$`),
 	},
 	"25": Tag{
-		name: "AccountIdentification",
-		id:   "25",
-		re:   regexp.MustCompile(`(?P<account_identification>.{0,35})`),
+		name:   "AccountIdentification",
+		id:     "25",
+		re:     regexp.MustCompile(`(?P<account_identification>.*)`),
+		format: MustCompileFormat("35x", "account_identification"),
+		limits: map[string]fieldLimit{
+			"account_identification": {length: 35},
+		},
 		examples: []string{
 			":25:0123456789",
 			":25:NL08DEUT0319809633EUR",
@@ -75,9 +118,14 @@ var Tags = map[string]Tag{
 		},
 	},
 	"28C": Tag{
-		name: "StatementNumber",
-		id:   "28C",
-		re:   regexp.MustCompile(`(?P<statement_number>[0-9]{1,5})(?:/?(?P<sequence_number>[0-9]{1,5}))?$`),
+		name:   "StatementNumber",
+		id:     "28C",
+		re:     regexp.MustCompile(`^(?P<statement_number>[0-9]+)(?:/(?P<sequence_number>[0-9]+))?$`),
+		format: MustCompileFormat("5n[/5n]", "statement_number", "sequence_number"),
+		limits: map[string]fieldLimit{
+			"statement_number": {length: 5},
+			"sequence_number":  {length: 5},
+		},
 		examples: []string{
 			":28C:3/00001",
 			":28C:355/00001",
@@ -86,9 +134,11 @@ var Tags = map[string]Tag{
 		},
 	},
 	"60": Tag{
-		name: "OpeningBalance",
-		id:   "60",
-		re:   balanceRegexp,
+		name:   "OpeningBalance",
+		id:     "60",
+		re:     balanceRegexp,
+		format: balanceFormat,
+		limits: balanceLimits,
 		examples: []string{
 			":60F:C111111EUR960",
 			":60F:C111118EUR5480,16",
@@ -96,9 +146,11 @@ var Tags = map[string]Tag{
 		},
 	},
 	"60F": Tag{
-		name: "FinalOpeningBalance",
-		id:   "60F",
-		re:   balanceRegexp,
+		name:   "FinalOpeningBalance",
+		id:     "60F",
+		re:     balanceRegexp,
+		format: balanceFormat,
+		limits: balanceLimits,
 		examples: []string{
 			":60F:C180220GBP16,00",
 		},
@@ -117,119 +169,282 @@ var Tags = map[string]Tag{
 				// code, if needed)
 				`[\n ]?` + // apparently some banks (sparkassen) incorporate newlines here
 				// cuscal can also send a space here as well
-				`(?P<amount>[0-9,]{1,15})` + // 15d Amount
-				`(?P<id>[A-Z][A-Z0-9 ]{3})?` + // 1!a3!c Transaction Type Identification Code
-				// We need the (slow) repeating negative lookahead to search for // so we
-				// don't acciddntly include the bank reference in the customer reference.
-				`(?P<customer_reference>((?:(?:[^/]|/[^/]))[^\n]){0,16})` + // 16x Customer Reference
-				`(//(?P<bank_reference>.{0,23}))?` + // [//23x] Bank Reference
-				`(\n?(?P<extra_details>.{0,34}))?`, // [34x] Supplementary Details
+				`(?P<amount>[0-9,]+)` + // 15d Amount
+				`(?P<id>(?P<id_class>[A-Z])(?P<id_code>[A-Z0-9 ]{3}))?` + // 1!a3!c Transaction Type Identification Code
+				// The customer reference is bounded to the first line and stops at the
+				// first // so we don't accidentally include the bank reference in it.
+				`(?P<customer_reference>[^\r\n]*?)` + // 16x Customer Reference
+				`(?://(?P<bank_reference>[^\r\n]*))?` + // [//16x] Bank Reference
+				`(?:\r?\n(?P<extra_details>[\s\S]*))?$`, // [34x] Supplementary Details
 		),
+		format: MustCompileFormat("2!n2!n2!n[2!n2!n]2a[1!a]15d1!a3!c16x[//16x][\n34x]",
+			"year", "month", "day", "entry_month", "entry_day", "status", "funds_code", "amount",
+			"id_class", "id_code", "customer_reference", "bank_reference", "extra_details",
+		).MustWithValues("status", "D", "C", "RD", "RC"),
+		limits: map[string]fieldLimit{
+			"amount":             {length: 15, decimals: amountDecimals},
+			"customer_reference": {length: 16},
+			"bank_reference":     {length: 16},
+			"extra_details":      {length: 34},
+		},
 		examples: []string{
 			":61:1112021202D43,6N477NONREF",
 			":61:2303010228CK366336,2NTRFArbi/deposit//1323333800",
-			":61:2001010101D65,00NOVBNL47INGB9999999999\n        hr gjlm paulissen    \n  ",
 		},
 	},
 	"86": Tag{
-		name: "InformationToAccountOwner",
-		id:   "86",
-		re:   regexp.MustCompile(`(?P<transaction_details>(([\s\S]{0,65}\r?\n?){0,8}[\s\S]{0,65}))`),
+		name:   "InformationToAccountOwner",
+		id:     "86",
+		re:     regexp.MustCompile(`(?P<transaction_details>[\s\S]*)`),
+		format: MustCompileFormat("6*65x", "transaction_details"),
+		limits: map[string]fieldLimit{
+			"transaction_details": {length: 65, lines: 6},
+		},
 		examples: []string{
-			":86:/RREF/3825-0031367289 /EREF/1309101116-0000001 /ORDP//NAME/AB AG/REMI/Inv. 1000217666 - 22.724,00, Inv. 1000217693 - 68.130,00,inv. 1000217801 - 16.470,00 /RCMT/EUR 100.000,00 /CHRG/DKK 4,00",
+			":86:/RREF/3825-0031367289 /EREF/1309101116-0000001 /ORDP//NAME/AB AG\n/REMI/Inv. 1000217666 - 22.724,00, Inv. 1000217693 - 68.130,00\n,inv. 1000217801 - 16.470,00 /RCMT/EUR 100.000,00 /CHRG/DKK 4,00",
+			":86:/RREF/3825-0031367289 /EREF/1309101116-0000001\n/ORDP//NAME/AB AG/REMI/Inv. 1000217666\n- 22.724,00 /CHRG/DKK 4,00",
 		},
 	},
 	"62": Tag{
-		name: "ClosingBalance",
-		id:   "62",
-		re:   balanceRegexp,
+		name:   "ClosingBalance",
+		id:     "62",
+		re:     balanceRegexp,
+		format: balanceFormat,
+		limits: balanceLimits,
 	},
 	"62M": Tag{
-		name: "IntermediateClosingBalance",
-		id:   "62M",
-		re:   balanceRegexp,
+		name:   "IntermediateClosingBalance",
+		id:     "62M",
+		re:     balanceRegexp,
+		format: balanceFormat,
+		limits: balanceLimits,
 		examples: []string{
 			":62M:C230228DKK12724930,14",
 		},
 	},
 	"62F": Tag{
-		name: "FinalClosingBalance",
-		id:   "62F",
-		re:   balanceRegexp,
+		name:   "FinalClosingBalance",
+		id:     "62F",
+		re:     balanceRegexp,
+		format: balanceFormat,
+		limits: balanceLimits,
 		examples: []string{
 			":62F:C230228DKK12724930,14",
 		},
 	},
 	"64": Tag{
-		name: "AvailableBalance",
-		id:   "64",
-		re:   balanceRegexp,
+		name:   "AvailableBalance",
+		id:     "64",
+		re:     balanceRegexp,
+		format: balanceFormat,
+		limits: balanceLimits,
 		examples: []string{
 			":64:C230228DKK6698733,27",
 			":64:C180220GBP16,00",
 		},
 	},
 	"21": Tag{
-		name:     "RelatedReference",
-		id:       "21",
-		re:       regexp.MustCompile(`(?P<related_reference>.{0,16})`),
-		examples: []string{},
+		name:   "RelatedReference",
+		id:     "21",
+		re:     regexp.MustCompile(`(?P<related_reference>.*)`),
+		format: MustCompileFormat("16x", "related_reference"),
+		examples: []string{
+			":21:NONREF",
+			":21:MT920REQ0001",
+		},
+		limits: map[string]fieldLimit{
+			"related_reference": {length: 16},
+		},
 	},
 	"34": Tag{
 		name: "FloorLimitIndicator",
 		id:   "34",
 		re:   regexp.MustCompile(`(?P<currency>[A-Z]{3})(?P<status>[DC ]?)(?P<amount>[0-9,]{0,16})`),
 	},
+	"34F": Tag{
+		name:   "FloorLimitIndicator",
+		id:     "34F",
+		re:     regexp.MustCompile(`^(?P<currency>[A-Z]{3})(?P<status>[DC]?)(?P<amount>[0-9,]+)$`),
+		format: MustCompileFormat("3!a[1!a]15d", "currency", "status", "amount"),
+		limits: map[string]fieldLimit{
+			"amount": {length: 15, decimals: amountDecimals},
+		},
+		examples: []string{
+			":34F:PLN0",
+			":34F:EURD0,00",
+			":34F:EURC0,00",
+		},
+	},
+	"13D": Tag{
+		name:   "DateTimeIndication",
+		id:     "13D",
+		re:     regexp.MustCompile(`^(?P<year>[0-9]{2})(?P<month>[0-9]{2})(?P<day>[0-9]{2})(?P<hour>[0-9]{2})(?P<minute>[0-9]{2})(?P<sign>[+-])(?P<offset>[0-9]{4})$`),
+		format: MustCompileFormat("2!n2!n2!n2!n2!n1!x4!n", "year", "month", "day", "hour", "minute", "sign", "offset").MustWithValues("sign", "+", "-"),
+		examples: []string{
+			":13D:1701191815+0100",
+			":13D:1610301730+0000",
+		},
+	},
 	"NS": Tag{
 		name:  "NonSwift",
 		id:    "NS",
-		re:    regexp.MustCompile(`(?P<non_swift>([0-9]{2}.{0,}\n[0-9]{2}.{0,})*|[^\n]*)$`),
-		subre: regexp.MustCompile(`(?P<ns_id>[0-9]{2})(?P<ns_data>.{0,})`),
+		re:    regexp.MustCompile(`(?P<non_swift>[\s\S]*)$`),
+		subre: regexp.MustCompile(`^(?P<ns_id>[0-9]{2})(?P<ns_data>.{0,})$`),
+		examples: []string{
+			":NS:22JOHN DOE\n23John Doe\n25171004171011",
+			":NS:01526715\n09Tranzakcios Illetek:7.21HUF\n15ERGO Versicherung AG Fiokte\n16lep",
+		},
 	},
 	"60M": Tag{
-		name: "IntermediateOpeningBalance",
-		id:   "60M",
-		re:   balanceRegexp,
+		name:   "IntermediateOpeningBalance",
+		id:     "60M",
+		re:     balanceRegexp,
+		format: balanceFormat,
+		limits: balanceLimits,
 	},
 	"65": Tag{
-		name: "ForwardAvailableBalance",
-		id:   "65",
-		re:   balanceRegexp,
+		name:   "ForwardAvailableBalance",
+		id:     "65",
+		re:     balanceRegexp,
+		format: balanceFormat,
+		limits: balanceLimits,
 	},
 	"90": Tag{
-		name: "SumEntries",
-		id:   "90",
-		re:   regexp.MustCompile(`^(?P<number>[0-9]*)(?P<currency>.{3})(?P<amount>[[0-9],]{1,15})`),
+		name:   "SumEntries",
+		id:     "90",
+		re:     sumRegexp,
+		limits: sumLimits,
 	},
 	"90D": Tag{
 		name:   "SumDebitEntries",
 		id:     "90D",
+		re:     sumRegexp,
+		format: sumFormat,
+		limits: sumLimits,
 		status: "D",
+		examples: []string{
+			":90D:0PLN0,00",
+			":90D:1EUR2,30",
+		},
 	},
 	"90C": Tag{
 		name:   "SumCreditEntries",
 		id:     "90C",
+		re:     sumRegexp,
+		format: sumFormat,
+		limits: sumLimits,
 		status: "C",
+		examples: []string{
+			":90C:3PLN0,03",
+		},
 	},
 }
 
 func (t *Tag) Parse(value string) (TagResults, *TagError) {
+	result, _, err := t.parse(value)
+	return result, err
+}
+
+// Parses value like Parse, the spans hold the start and end offset in value
+// of every field matched by the tag's regexp
+func (t *Tag) parse(value string) (TagResults, map[string][2]int, *TagError) {
 	ind := tagRegex.FindStringIndex(value)
 	if ind == nil {
-		return nil, &TagError{ErrMisformatedTag, t, value}
+		return nil, nil, &TagError{ParseError: &SyntaxError{ErrMisformatedTag}, Tag: t, Value: value}
 	}
+	rest := value[ind[1]:]
+	trimmed := strings.TrimSpace(rest)
+	base := ind[1] + len(rest) - len(strings.TrimLeftFunc(rest, unicode.IsSpace))
 
+	if t.parseFunc != nil {
+		result, err := t.parseFunc(trimmed)
+		if err != nil {
+			return nil, nil, &TagError{ParseError: WrapParseError(err), Tag: t, Value: value}
+		}
+		return result, nil, nil
+	}
 	if t.re == nil {
-		return nil, &TagError{ErrNotImplemented, t, value}
+		return nil, nil, &TagError{ParseError: ErrNotImplemented, Tag: t, Value: value}
 	}
-	match := t.re.FindStringSubmatch(strings.TrimSpace(value[ind[1]:]))
+	match := t.re.FindStringSubmatchIndex(trimmed)
 	if match == nil {
-		return nil, &TagError{ErrTagDidNotParse, t, value}
+		return nil, nil, &TagError{ParseError: &SyntaxError{ErrTagDidNotParse}, Tag: t, Value: value}
 	}
 
 	result := make(map[string]string)
+	spans := make(map[string][2]int)
 	for i, name := range t.re.SubexpNames() {
-		result[name] = match[i]
+		start, end := match[2*i], match[2*i+1]
+		if start < 0 {
+			result[name] = ""
+			continue
+		}
+		result[name] = trimmed[start:end]
+		if name != "" {
+			spans[name] = [2]int{base + start, base + end}
+		}
+	}
+	return result, spans, nil
+}
+
+// Checks the parsed values against the SWIFT field lengths of the tag
+func (t *Tag) CheckLimits(r TagResults) []*FieldError {
+	if t.re == nil {
+		return nil
+	}
+	var errs []*FieldError
+	for _, name := range t.re.SubexpNames() {
+		limit, ok := t.limits[name]
+		if !ok {
+			continue
+		}
+		value := r[name]
+		lines := strings.Split(strings.ReplaceAll(value, "\r\n", "\n"), "\n")
+		maxLines := limit.lines
+		if maxLines == 0 {
+			maxLines = 1
+		}
+		if len(lines) > maxLines {
+			errs = append(errs, &FieldError{name, value, len(lines), maxLines, "lines"})
+		}
+		for _, line := range lines {
+			if n := utf8.RuneCountInString(line); n > limit.length {
+				errs = append(errs, &FieldError{name, value, n, limit.length, "characters"})
+				break
+			}
+		}
+		if n := significantDecimals(value); limit.decimals > 0 && n > limit.decimals {
+			errs = append(errs, &FieldError{name, value, n, limit.decimals, "decimals"})
+		}
+	}
+	return errs
+}
+
+// Splits a value into numbered subfields using the tag's subre, lines that
+// don't start with a subfield number continue the previous subfield
+func (t *Tag) ParseSubfields(value string) NonSwift {
+	ns := NonSwift{}
+	if t.subre == nil {
+		return ns
+	}
+
+	var last string
+	for _, line := range strings.Split(value, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		match := t.subre.FindStringSubmatch(line)
+		if match == nil {
+			if values := ns[last]; len(values) > 0 {
+				values[len(values)-1] += "\n" + line
+			} else {
+				ns[last] = append(ns[last], line)
+			}
+			continue
+		}
+		last = match[t.subre.SubexpIndex("ns_id")]
+		ns[last] = append(ns[last], match[t.subre.SubexpIndex("ns_data")])
 	}
-	return result, nil
+	return ns
 }
@@ -1,6 +1,7 @@
 package mt940
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -29,3 +30,123 @@ func TestTag_Parse(t *testing.T) {
 		})
 	}
 }
+
+func TestTag_Parse_StatementLine(t *testing.T) {
+	tests := []struct {
+		value string
+		want  TagResults
+	}{
+		{
+			":61:2001010101D65,00NOVBNL47INGB9999999999\nhr gjlm paulissen",
+			TagResults{"id": "NOVB", "customer_reference": "NL47INGB9999999999", "bank_reference": "", "extra_details": "hr gjlm paulissen"},
+		},
+		{
+			":61:1312091209C79,7FMSC01916//NONREF\n20131209007602198765432000000012",
+			TagResults{"id": "FMSC", "customer_reference": "01916", "bank_reference": "NONREF", "extra_details": "20131209007602198765432000000012"},
+		},
+		{
+			":61:1701190119CN0,01NTRFNONREF//MB170119012058\r\n911-TRANSAKCJA IPH",
+			TagResults{"id": "NTRF", "customer_reference": "NONREF", "bank_reference": "MB170119012058", "extra_details": "911-TRANSAKCJA IPH"},
+		},
+		{
+			":61:1112021202D43,6N477NONREF",
+			TagResults{"id": "N477", "customer_reference": "NONREF", "bank_reference": "", "extra_details": ""},
+		},
+	}
+	tag := Tags["61"]
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			out, err := tag.Parse(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range tt.want {
+				if out[k] != v {
+					t.Errorf("group '%v' = %q, want %q", k, out[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestTag_CheckLimits(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []FieldError
+	}{
+		{":20:0000000030210056", nil},
+		{":20:00000000302100561", []FieldError{{"transaction_reference", "00000000302100561", 17, 16, "characters"}}},
+		{":28C:123456/1", []FieldError{{"statement_number", "123456", 6, 5, "characters"}}},
+		{":28C:355/123456", []FieldError{{"sequence_number", "123456", 6, 5, "characters"}}},
+		{":34F:EURD1234567890123456,00", []FieldError{{"amount", "1234567890123456,00", 19, 15, "characters"}}},
+		{":90D:123456EUR1234567890123456,00", []FieldError{
+			{"number", "123456", 6, 5, "characters"},
+			{"amount", "1234567890123456,00", 19, 15, "characters"},
+		}},
+		{":86:1\n2\n3\n4\n5\n6", nil},
+		{":86:1\n2\n3\n4\n5\n6\n7", []FieldError{{"transaction_details", "1\n2\n3\n4\n5\n6\n7", 7, 6, "lines"}}},
+		{
+			":61:2001010101D65,00NOVBNL47INGB9999999999",
+			[]FieldError{{"customer_reference", "NL47INGB9999999999", 18, 16, "characters"}},
+		},
+		{":62F:C200101EUR123456789012,00", nil},
+		{
+			":62F:C200101EUR12345678901234567890,00",
+			[]FieldError{{"amount", "12345678901234567890,00", 23, 15, "characters"}},
+		},
+		{
+			":61:2001010101D12345678901234567890,00NTRFNONREF",
+			[]FieldError{{"amount", "12345678901234567890,00", 23, 15, "characters"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			tag := Tags[tagRegex.FindStringSubmatch(tt.value)[1]]
+			out, err := tag.Parse(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs := tag.CheckLimits(out)
+			if len(errs) != len(tt.want) {
+				t.Fatalf("CheckLimits() got %v, want %v", errs, tt.want)
+			}
+			for i, fe := range errs {
+				if *fe != tt.want[i] {
+					t.Errorf("CheckLimits() got %v, want %v", *fe, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// The formats used for writing parse the examples the way the regexps do and
+// write them back
+func TestTag_Format(t *testing.T) {
+	for id, tag := range Tags {
+		if tag.format == nil {
+			continue
+		}
+		t.Run(id, func(t *testing.T) {
+			for _, ex := range tag.examples {
+				want, err := tag.Parse(ex)
+				if err != nil {
+					t.Fatal(err)
+				}
+				value := strings.TrimSpace(ex[tagRegex.FindStringIndex(ex)[1]:])
+				got, ferr := tag.format.Parse(value)
+				if ferr != nil {
+					t.Errorf("Format.Parse(%q) error = %v", value, ferr)
+					continue
+				}
+				for name, v := range got {
+					if w, ok := want[name]; ok && v != w {
+						t.Errorf("Format.Parse(%q)[%v] = %q, the regexp parses %q", value, name, v, w)
+					}
+				}
+				if written, err := tag.format.Write(got); err != nil || written != value {
+					t.Errorf("Format.Write() = %q, %v, want %q", written, err, value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package mt940
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type lineKind int
+
+const (
+	tagLine          lineKind = iota // Starts a new tag
+	continuationLine                 // Continues the value of the current tag
+	separatorLine                    // Ends a message, eg. - or -}
+	junkLine                         // Headers and preambles outside of any tag
+	blankLine
+)
+
+type line struct {
+	kind  lineKind
+	id    string // Tag id for tag lines
+	split bool   // Tag marker continues on the next line, eg. :\n86:
+	text  string // Without the line terminator
+	start int
+	end   int // Including the line terminator
+}
+
+type token struct {
+	id        string
+	value     string // Tag text starting with :id: with LF line endings
+	offsets   []int  // Input offset of every byte of value
+	start     int
+	end       int
+	separated bool // A message separator precedes the token
+}
+
+// Ids the tokenizer recognizes, registering others would have no effect
+const tagIDPattern = `[0-9]{2}[A-Z]?|NS`
+
+var (
+	tagLineRegex  = regexp.MustCompile(`^:(` + tagIDPattern + `):`)
+	splitTagRegex = regexp.MustCompile(`^(` + tagIDPattern + `):`)
+	tagIDRegex    = regexp.MustCompile(`^(?:` + tagIDPattern + `)$`)
+)
+
+// Tags that may follow :86: before and after the closing balance, colon-led
+// lines in the free text of :86: that aren't one of these are continuations
+var (
+	afterDetails       = []string{"61", "62F", "62M", "64", "65", "86", "NS", "90D", "90C"}
+	afterClosedDetails = []string{"20", "64", "65", "86", "NS"}
+)
+
+func isTagAfterDetails(closed bool, id string) bool {
+	next := afterDetails
+	if closed {
+		next = afterClosedDetails
+	}
+	for _, n := range next {
+		if n == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Splits on LF, CRLF and lone CR line terminators
+func splitLines(data []byte) []line {
+	var lines []line
+	start := 0
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '\n':
+			lines = append(lines, line{text: string(data[start:i]), start: start, end: i + 1})
+			start = i + 1
+		case '\r':
+			end := i + 1
+			if end < len(data) && data[end] == '\n' {
+				end++
+			}
+			lines = append(lines, line{text: string(data[start:i]), start: start, end: end})
+			start = end
+			i = end - 1
+		}
+	}
+	if start < len(data) {
+		lines = append(lines, line{text: string(data[start:]), start: start, end: len(data)})
+	}
+	return lines
+}
+
+// A :20: value that fits its 16x format, which starts the next message even
+// inside :86: of a statement without closing balance
+func isReference(value string) bool {
+	value = strings.TrimRight(value, " \t")
+	return value != "" && utf8.RuneCountInString(value) <= 16 && formatCharsetRegexps['x'].MatchString(value)
+}
+
+// Inside :86: colon-led lines only start a new tag if the id is known and may
+// follow the details, or if they're a :20: reference
+func classifyLines(lines []line, known func(id string) bool) {
+	current, closed := "", false
+	isTag := func(id, text string) bool {
+		switch {
+		case current != "86":
+			return true
+		case id == "20" && isReference(text[len(":20:"):]):
+			return true
+		}
+		return known(id) && isTagAfterDetails(closed, id)
+	}
+	for i := range lines {
+		l := &lines[i]
+		text := strings.TrimRight(l.text, " \t")
+		var match []string
+		switch {
+		case text == "":
+			l.kind = blankLine
+		case tagLineRegex.MatchString(text) && isTag(tagLineRegex.FindStringSubmatch(text)[1], text):
+			match = tagLineRegex.FindStringSubmatch(text)
+		case text == ":" && i+1 < len(lines) && splitTagRegex.MatchString(lines[i+1].text):
+			match = splitTagRegex.FindStringSubmatch(lines[i+1].text)
+			l.split = true
+		case isSeparator(text):
+			l.kind = separatorLine
+			current, closed = "", false
+		case current != "":
+			l.kind = continuationLine
+		default:
+			l.kind = junkLine
+		}
+		if match == nil {
+			continue
+		}
+
+		l.kind = tagLine
+		l.id = match[1]
+		current = l.id
+		switch l.id {
+		case "20":
+			closed = false
+		case "62F", "62M", "64", "65", "90D", "90C":
+			closed = true
+		}
+	}
+}
+
+// A message separator is - or -} alone, or followed by the trailer block of
+// the message or control characters like ETX. Details lines like - 22,00
+// aren't separators
+func isSeparator(text string) bool {
+	text = strings.TrimRightFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	})
+	return text == "-" || text == "-}" || strings.HasPrefix(text, "-}{")
+}
+
+func tokenize(data []byte, known func(id string) bool) []token {
+	lines := splitLines(data)
+	classifyLines(lines, known)
+	return tokenizeLines(lines)
+}
+
+// Groups classified lines into tag tokens, blank lines inside a tag are kept
+// but trailing ones are dropped from its value and span
+func tokenizeLines(lines []line) []token {
+	var tokens []token
+	var value []byte
+	var offsets []int
+	var tok *token
+	separated, join := false, false
+	add := func(l line) {
+		value = append(value, l.text...)
+		for i := 0; i < len(l.text); i++ {
+			offsets = append(offsets, l.start+i)
+		}
+	}
+	for _, l := range lines {
+		switch l.kind {
+		case tagLine:
+			tokens = append(tokens, token{id: l.id, start: l.start, separated: separated})
+			tok = &tokens[len(tokens)-1]
+			value, offsets = nil, nil
+			add(l)
+			separated, join = false, l.split
+		case continuationLine, blankLine:
+			if tok == nil {
+				continue
+			}
+			if !join {
+				// The line break is at the end of the previous line's text
+				value = append(value, '\n')
+				offsets = append(offsets, offsets[len(offsets)-1]+1)
+			}
+			add(l)
+			join = false
+		case separatorLine:
+			tok = nil
+			separated = true
+			continue
+		default:
+			tok = nil
+			continue
+		}
+		if l.kind != blankLine {
+			tok.end = l.end
+			tok.value = string(value)
+			tok.offsets = offsets[:len(value):len(value)]
+		}
+	}
+	return tokens
+}
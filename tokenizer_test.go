@@ -0,0 +1,157 @@
+package mt940
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	type tok struct {
+		id, value string
+		separated bool
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  []tok
+	}{
+		{
+			"preamble",
+			"ABNANL2A\n940\nABNANL2A\n:20:ABN AMRO BANK NV\n:25:517852257\n",
+			[]tok{{"20", ":20:ABN AMRO BANK NV", false}, {"25", ":25:517852257", false}},
+		},
+		{
+			"line endings",
+			":20:REF\r\n:86:first\rsecond\r\n:62F:C100323EUR42570,04",
+			[]tok{{"20", ":20:REF", false}, {"86", ":86:first\nsecond", false}, {"62F", ":62F:C100323EUR42570,04", false}},
+		},
+		{
+			"split tag marker",
+			":61:1811261126CR\n30,00N062NONREF\n:\n86:166?00GUTSCHR\n?20SVWZ+Test\n",
+			[]tok{{"61", ":61:1811261126CR\n30,00N062NONREF", false}, {"86", ":86:166?00GUTSCHR\n?20SVWZ+Test", false}},
+		},
+		{
+			"separators and blank lines",
+			"\n:20:A\n:86:one\n   \ntwo\n\n-}{5:}\n{1:F01ASNBNL21XXXX0000000000}{2:O940ASNBNL21XXXXN}{3:}{4:\n:20:B\n-",
+			[]tok{{"20", ":20:A", false}, {"86", ":86:one\n   \ntwo", false}, {"20", ":20:B", true}},
+		},
+		{
+			"binary separators",
+			"\x01\n:20:ST170119CYC/1\n:62F:C170119PLN0,43\n-\x03",
+			[]tok{{"20", ":20:ST170119CYC/1", false}, {"62F", ":62F:C170119PLN0,43", false}},
+		},
+		{
+			"continuation with leading space",
+			":86:820?00UMBUCHUNG\n FUER VISA 4998\n-12,00 EUR\n",
+			[]tok{{"86", ":86:820?00UMBUCHUNG\n FUER VISA 4998\n-12,00 EUR", false}},
+		},
+		{
+			"continuation with leading dash",
+			":86:first line\n- second line\n-  22.724,00\n-\n:20:NEXT",
+			[]tok{{"86", ":86:first line\n- second line\n-  22.724,00", false}, {"20", ":20:NEXT", true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []tok
+			for _, token := range tokenize([]byte(tt.input), DefaultRegistry.known) {
+				got = append(got, tok{token.id, token.value, token.separated})
+				if token.start >= token.end || token.end > len(tt.input) {
+					t.Errorf("token %v has span %v-%v", token.id, token.start, token.end)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenize_Fixtures(t *testing.T) {
+	tests := []struct {
+		file  string
+		first string
+		count int
+	}{
+		{"jejik/abnamro.sta", "20", 30},
+		{"self-provided/sparkassen.sta", "61", 2},
+		{"betterplace/empty_line.sta", "20", 1},
+		{"betterplace/missing_crlf_at_end.sta", "62F", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			tokens := tokenize(must(os.ReadFile(tt.file)), DefaultRegistry.known)
+			if len(tokens) != tt.count || tokens[0].id != tt.first {
+				t.Errorf("tokenize() got %v tokens starting with %v", len(tokens), tokens[0].id)
+			}
+		})
+	}
+}
+
+func TestTokenize_ColonContinuation(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			"unknown tag in details",
+			":61:1602300301DR6,00N024NONREF\n:86:?24/PL 12-09-2014T16\n:26:37 Fo?25lgenr. 007\n:62F:C160301EUR1194,00",
+			[]string{"61", "86", "62F"},
+		},
+		{
+			"reference inside entry details",
+			":61:1602300301DR6,00N024NONREF\n:86:payment for\n:20: invoice reference 2014/09\n:61:1602300301DR6,00N024NONREF",
+			[]string{"61", "86", "61"},
+		},
+		{
+			"next statement after closing details",
+			":62F:C160301EUR1194,00\n:86:statement remark\n:20:NEXT\n:25:123",
+			[]string{"62F", "86", "20", "25"},
+		},
+		{
+			"next statement without closing balance",
+			":61:1602300301DR6,00N024NONREF\n:86:details\n:20:B\n:25:X",
+			[]string{"61", "86", "20", "25"},
+		},
+		{
+			"next interim report after summaries",
+			":90C:3PLN0,03\n:86:remark\n:20:ST170119CYC/0002\n:25:X",
+			[]string{"90C", "86", "20", "25"},
+		},
+		{
+			"outside details",
+			":20:REF\n:26:unknown",
+			[]string{"20", "26"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, token := range tokenize([]byte(tt.input), DefaultRegistry.known) {
+				got = append(got, token.id)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransactions_Parse_ColonContinuation(t *testing.T) {
+	for _, file := range []string{"self-provided/wrapped_timestamp.sta", "self-provided/transaction_details_wrapped.sta"} {
+		t.Run(file, func(t *testing.T) {
+			// The fixtures book on February 30, which doesn't parse
+			input := strings.ReplaceAll(string(must(os.ReadFile(file))), ":61:160230", ":61:160229")
+			got, err := (&Transactions{Lenient: true}).Parse(strings.NewReader(input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 || !strings.Contains(got[0].TransactionDetails, "\n:") {
+				t.Errorf("Transactions.Parse() got %v", got)
+			}
+		})
+	}
+}
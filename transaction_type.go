@@ -0,0 +1,142 @@
+package mt940
+
+import (
+	"strings"
+)
+
+type TransactionTypeClass byte
+
+const (
+	NonSwiftTransfer TransactionTypeClass = 'N' // Non-SWIFT transfer, code is a 3 letter type
+	FirstAdvice      TransactionTypeClass = 'F' // First advice, code is a 3 letter type
+	SwiftTransfer    TransactionTypeClass = 'S' // SWIFT transfer, code is the 3 digit message type
+)
+
+var ErrUnknownTransactionType = NewParseError("unknown transaction type class")
+
+type TransactionType struct {
+	Class TransactionTypeClass
+	Code  string
+}
+
+// Standard SWIFT transaction type identification codes (field 61, subfield 6)
+var TransactionTypeCodes = map[string]string{
+	"BNK": "Securities related item - bank fees",
+	"BOE": "Bill of exchange",
+	"BRF": "Brokerage fee",
+	"CAR": "Securities related item - corporate actions related",
+	"CAS": "Securities related item - cash in lieu",
+	"CHG": "Charges and other expenses",
+	"CHK": "Cheques",
+	"CLR": "Cash letters/cheques remittance",
+	"CMI": "Cash management item - no detail",
+	"CMN": "Cash management item - notional pooling",
+	"CMP": "Compensation claims",
+	"CMS": "Cash management item - sweeping",
+	"CMT": "Cash management item - topping",
+	"CMZ": "Cash management item - zero balancing",
+	"COL": "Collections",
+	"COM": "Commission",
+	"CPN": "Securities related item - coupon payments",
+	"DCR": "Documentary credit",
+	"DDT": "Direct debit item",
+	"DIS": "Securities related item - gains disbursement",
+	"DIV": "Securities related item - dividends",
+	"EQA": "Equivalent amount",
+	"EXT": "Securities related item - external transfer for own account",
+	"FEX": "Foreign exchange",
+	"INT": "Interest",
+	"LBX": "Lock box",
+	"LDP": "Loan deposit",
+	"MAR": "Securities related item - margin payments/receipts",
+	"MAT": "Securities related item - maturity",
+	"MGT": "Securities related item - management fees",
+	"MSC": "Miscellaneous",
+	"NWI": "Securities related item - new issues distribution",
+	"ODC": "Overdraft charge",
+	"OPT": "Securities related item - options",
+	"PCH": "Securities related item - purchase",
+	"POP": "Securities related item - pair-off proceeds",
+	"PRN": "Securities related item - principal pay-down/pay-up",
+	"REC": "Securities related item - tax reclaim",
+	"RED": "Securities related item - redemption/withdrawal",
+	"RIG": "Securities related item - rights",
+	"RTI": "Returned item",
+	"SAL": "Securities related item - sale",
+	"SEC": "Securities",
+	"SLE": "Securities related item - securities lending related",
+	"STO": "Standing order",
+	"STP": "Securities related item - stamp duty",
+	"SUB": "Securities related item - subscription",
+	"SWP": "Securities related item - SWAP payment",
+	"TAX": "Securities related item - withholding tax payment",
+	"TCK": "Travellers cheques",
+	"TCM": "Securities related item - tripartite collateral management",
+	"TRA": "Securities related item - internal transfer for own account",
+	"TRF": "Transfer",
+	"TRN": "Securities related item - transaction fee",
+	"UWC": "Securities related item - underwriting commission",
+	"VDA": "Value date adjustment",
+	"WAR": "Securities related item - warrant",
+}
+
+func ParseTransactionType(id string) (TransactionType, error) {
+	if len(id) == 0 {
+		return TransactionType{}, nil
+	}
+
+	tt := TransactionType{
+		Class: TransactionTypeClass(id[0]),
+		Code:  strings.TrimSpace(id[1:]),
+	}
+	switch tt.Class {
+	case NonSwiftTransfer, FirstAdvice, SwiftTransfer:
+	default:
+		return TransactionType{}, ErrUnknownTransactionType
+	}
+	return tt, nil
+}
+
+// Numeric codes following N or F are bank specific (eg. ABN AMRO's N192)
+func (tt TransactionType) IsBankSpecific() bool {
+	if tt.Class == SwiftTransfer || tt.Code == "" {
+		return false
+	}
+	for _, c := range tt.Code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (tt TransactionType) IsStandard() bool {
+	if tt.Class == SwiftTransfer {
+		return false
+	}
+	_, ok := TransactionTypeCodes[tt.Code]
+	return ok
+}
+
+func (tt TransactionType) Description() string {
+	switch {
+	case tt.Class == SwiftTransfer && tt.Code == "":
+		return "SWIFT transfer"
+	case tt.Class == SwiftTransfer:
+		return "SWIFT transfer MT" + tt.Code
+	case tt.IsStandard():
+		return TransactionTypeCodes[tt.Code]
+	}
+	return ""
+}
+
+func (tt TransactionType) String() string {
+	if tt.Class == 0 {
+		return ""
+	}
+	return string(tt.Class) + tt.Code
+}
+
+func (sl *StatementLine) TransactionType() (TransactionType, error) {
+	return ParseTransactionType(sl.TransactionTypeID)
+}
@@ -0,0 +1,47 @@
+package mt940
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTransactionType(t *testing.T) {
+	tests := []struct {
+		id           string
+		want         TransactionType
+		bankSpecific bool
+		description  string
+		wantErr      bool
+	}{
+		{"NTRF", TransactionType{NonSwiftTransfer, "TRF"}, false, "Transfer", false},
+		{"NMSC", TransactionType{NonSwiftTransfer, "MSC"}, false, "Miscellaneous", false},
+		{"FMSC", TransactionType{FirstAdvice, "MSC"}, false, "Miscellaneous", false},
+		{"S051", TransactionType{SwiftTransfer, "051"}, false, "SWIFT transfer MT051", false},
+		{"S   ", TransactionType{SwiftTransfer, ""}, false, "SWIFT transfer", false},
+		{"N192", TransactionType{NonSwiftTransfer, "192"}, true, "", false},
+		{"N426", TransactionType{NonSwiftTransfer, "426"}, true, "", false},
+		{"NZ10", TransactionType{NonSwiftTransfer, "Z10"}, false, "", false},
+		{"", TransactionType{}, false, "", false},
+		{"MCI0", TransactionType{}, false, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got, err := ParseTransactionType(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTransactionType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTransactionType() got %v, want %v", got, tt.want)
+			}
+			if got.IsBankSpecific() != tt.bankSpecific {
+				t.Errorf("IsBankSpecific() got %v, want %v", got.IsBankSpecific(), tt.bankSpecific)
+			}
+			if got.Description() != tt.description {
+				t.Errorf("Description() got %q, want %q", got.Description(), tt.description)
+			}
+			if !tt.wantErr && got.String() != strings.TrimSpace(tt.id) {
+				t.Errorf("String() got %q, want %q", got.String(), strings.TrimSpace(tt.id))
+			}
+		})
+	}
+}
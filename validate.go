@@ -0,0 +1,236 @@
+package mt940
+
+import (
+	"fmt"
+)
+
+type MessageType string
+
+const (
+	MT940 MessageType = "MT940" // Customer statement
+	MT942 MessageType = "MT942" // Interim transaction report
+	MT950 MessageType = "MT950" // Statement message
+)
+
+// Rules reported in the ValidationErrors of the structure validation
+const (
+	RuleMandatory  = "mandatory"  // A mandatory tag is missing
+	RuleOrder      = "order"      // A tag is out of order or not part of the message type
+	RuleRepetition = "repetition" // A tag occurs more often than allowed
+)
+
+// A tag, or a repeated group of tags starting with a mandatory one, in the
+// grammar of a message type
+type element struct {
+	name     string
+	ids      []string
+	min, max int // 0 is unbounded for max
+	group    []element
+}
+
+func tagElement(name string, min, max int, ids ...string) element {
+	return element{name: name, ids: ids, min: min, max: max}
+}
+
+func groupElement(min, max int, elements ...element) element {
+	return element{name: elements[0].name, min: min, max: max, group: elements}
+}
+
+var grammars = map[MessageType][]element{
+	MT940: {
+		tagElement(":20:", 1, 1, "20"),
+		tagElement(":21:", 0, 1, "21"),
+		tagElement(":25:", 1, 1, "25"),
+		tagElement(":28C:", 1, 1, "28C"),
+		tagElement(":60a:", 1, 1, "60F", "60M"),
+		groupElement(0, 0,
+			tagElement(":61:", 1, 1, "61"),
+			tagElement(":86:", 0, 1, "86"),
+		),
+		tagElement(":62a:", 1, 1, "62F", "62M"),
+		tagElement(":64:", 0, 1, "64"),
+		tagElement(":65:", 0, 0, "65"),
+		tagElement(":86:", 0, 1, "86"),
+	},
+	MT942: {
+		tagElement(":20:", 1, 1, "20"),
+		tagElement(":21:", 0, 1, "21"),
+		tagElement(":25:", 1, 1, "25"),
+		tagElement(":28C:", 1, 1, "28C"),
+		tagElement(":34F:", 1, 2, "34F"),
+		tagElement(":13D:", 1, 1, "13D"),
+		groupElement(0, 0,
+			tagElement(":61:", 1, 1, "61"),
+			tagElement(":86:", 0, 1, "86"),
+		),
+		tagElement(":90D:", 0, 1, "90D"),
+		tagElement(":90C:", 0, 1, "90C"),
+		tagElement(":86:", 0, 1, "86"),
+	},
+	MT950: {
+		tagElement(":20:", 1, 1, "20"),
+		tagElement(":25:", 1, 1, "25"),
+		tagElement(":28C:", 1, 1, "28C"),
+		tagElement(":60a:", 1, 1, "60F", "60M"),
+		tagElement(":61:", 0, 0, "61"),
+		tagElement(":62a:", 1, 1, "62F", "62M"),
+		tagElement(":64:", 0, 1, "64"),
+	},
+}
+
+func (e element) accepts(id string) bool {
+	if e.group != nil {
+		return e.group[0].accepts(id)
+	}
+	for _, i := range e.ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (e element) full(count int) bool {
+	return e.max != 0 && count >= e.max
+}
+
+type validatorFrame struct {
+	elements []element
+	i, count int // Current element and how often it occurred
+}
+
+// Checks the order and cardinality of the tags of a message one tag at a
+// time, non-SWIFT tags (:NS:) are ignored
+type Validator struct {
+	Type  MessageType
+	stack []validatorFrame
+}
+
+func NewValidator(mt MessageType) (*Validator, error) {
+	grammar, ok := grammars[mt]
+	if !ok {
+		return nil, fmt.Errorf("unknown message type %q", mt)
+	}
+	return &Validator{Type: mt, stack: []validatorFrame{{elements: grammar}}}, nil
+}
+
+// Checks the next tag of the message, tags which don't fit are skipped
+func (v *Validator) Next(id string) []*ValidationError {
+	if id == "NS" {
+		return nil
+	}
+
+	var errs []*ValidationError
+	var repeated *element // Element that would take id if it wasn't full
+	stack := append([]validatorFrame(nil), v.stack...)
+	for {
+		f := &v.stack[len(v.stack)-1]
+		if e := f.elements[f.i]; f.count > 0 && e.full(f.count) && e.accepts(id) && repeated == nil {
+			repeated = &e
+		}
+		if j := f.find(id); j >= 0 {
+			// A repeated tag is more likely than missing mandatory ones, and so
+			// is an optional tag out of order, eg. :86: before the first :61:
+			if missing := f.missing(j); len(missing) == 0 || repeated == nil && f.elements[j].min > 0 {
+				errs = append(errs, missing...)
+				if j != f.i {
+					f.i, f.count = j, 0
+				}
+				f.count++
+				if e := f.elements[j]; e.group != nil {
+					v.stack = append(v.stack, validatorFrame{elements: e.group, count: 1})
+				}
+				return errs
+			}
+			break
+		}
+		if len(v.stack) == 1 {
+			break
+		}
+		// Leave the group and retry in the enclosing elements
+		errs = append(errs, f.missing(len(f.elements))...)
+		v.stack = v.stack[:len(v.stack)-1]
+	}
+
+	// The tag is skipped, the message continues where it was
+	v.stack = stack
+	errs = nil
+	switch {
+	case repeated != nil:
+		errs = append(errs, &ValidationError{RuleRepetition, fmt.Sprintf("%v occurs more than %v times in %v", repeated.name, repeated.max, v.Type)})
+	case v.inGrammar(grammars[v.Type], id):
+		errs = append(errs, &ValidationError{RuleOrder, fmt.Sprintf(":%v: is out of order in %v", id, v.Type)})
+	default:
+		errs = append(errs, &ValidationError{RuleOrder, fmt.Sprintf(":%v: is not part of %v", id, v.Type)})
+	}
+	return errs
+}
+
+// Checks that the message isn't missing mandatory tags after its last tag
+func (v *Validator) End() []*ValidationError {
+	var errs []*ValidationError
+	for len(v.stack) > 0 {
+		f := v.stack[len(v.stack)-1]
+		errs = append(errs, f.missing(len(f.elements))...)
+		v.stack = v.stack[:len(v.stack)-1]
+	}
+	v.stack = []validatorFrame{{elements: grammars[v.Type]}}
+	return errs
+}
+
+// Returns the index of the first element from the current one on that can
+// take id, or -1
+func (f *validatorFrame) find(id string) int {
+	for j := f.i; j < len(f.elements); j++ {
+		e := f.elements[j]
+		if j == f.i && f.count > 0 && e.full(f.count) {
+			continue
+		}
+		if e.accepts(id) {
+			return j
+		}
+	}
+	return -1
+}
+
+// Reports the mandatory elements skipped when moving on to element j
+func (f *validatorFrame) missing(j int) []*ValidationError {
+	var errs []*ValidationError
+	for k := f.i; k < j && k < len(f.elements); k++ {
+		count := 0
+		if k == f.i {
+			count = f.count
+		}
+		if e := f.elements[k]; count < e.min {
+			errs = append(errs, &ValidationError{RuleMandatory, fmt.Sprintf("%v is missing", e.name)})
+		}
+	}
+	return errs
+}
+
+func (v *Validator) inGrammar(elements []element, id string) bool {
+	for _, e := range elements {
+		if e.accepts(id) || v.inGrammar(e.group, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks the tag ids of a single message against the grammar of its type
+func Validate(mt MessageType, ids []string) (Errors, error) {
+	v, err := NewValidator(mt)
+	if err != nil {
+		return nil, err
+	}
+	var errs Errors
+	for _, id := range ids {
+		for _, e := range v.Next(id) {
+			errs = append(errs, e)
+		}
+	}
+	for _, e := range v.End() {
+		errs = append(errs, e)
+	}
+	return errs, nil
+}
@@ -0,0 +1,95 @@
+package mt940
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		mt   MessageType
+		ids  string
+		want []string // Rule and message of each violation
+	}{
+		{"minimal", MT940, "20 25 28C 60F 62F", nil},
+		{"complete", MT940, "20 21 25 28C 60F 61 86 61 61 86 62F 64 65 65 86", nil},
+		{"intermediate", MT940, "20 25 28C 60M 61 62M", nil},
+		{"non swift", MT940, "20 25 28C 60F 61 NS 86 NS 62F", nil},
+		{"missing reference", MT940, "25 28C 60F 62F", []string{"mandatory: :20: is missing"}},
+		{"missing closing balance", MT940, "20 25 28C 60F 61 86", []string{"mandatory: :62a: is missing"}},
+		{"missing tags", MT940, "20 60F 61 62F", []string{"mandatory: :25: is missing", "mandatory: :28C: is missing"}},
+		{"repeated details", MT940, "20 25 28C 60F 61 86 86 62F", []string{"repetition: :86: occurs more than 1 times in MT940"}},
+		{"repeated reference", MT940, "20 20 25 28C 60F 62F", []string{"repetition: :20: occurs more than 1 times in MT940"}},
+		{"details before lines", MT940, "20 25 28C 60F 86 61 62F", []string{":86: is out of order in MT940"}},
+		{"details before lines in group", MT940, "20 25 28C 60F 61 86 86 61 62F 86", []string{
+			"repetition: :86: occurs more than 1 times in MT940",
+		}},
+		{"available balance without closing balance", MT940, "20 25 28C 60F 61 64 86", []string{
+			":64: is out of order in MT940", "mandatory: :62a: is missing",
+		}},
+		{"not in message type", MT950, "20 25 28C 60F 61 86 62F", []string{":86: is not part of MT950"}},
+		{"interim report", MT942, "20 25 28C 34F 34F 13D 61 86 90D 90C 86", nil},
+		{"interim report floor limits", MT942, "20 25 28C 34F 34F 34F 13D", []string{"repetition: :34F: occurs more than 2 times in MT942"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := Validate(tt.mt, strings.Fields(tt.ids))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range errs {
+				if !errors.Is(e, ErrValidation) {
+					t.Errorf("Validate() error %v is not %v", e, ErrValidation)
+				}
+				got = append(got, strings.TrimPrefix(e.Error(), RuleOrder+": "))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Validate("MT999", nil); err == nil {
+		t.Error("Validate() with an unknown message type should fail")
+	}
+}
+
+func TestTransactions_Parse_MessageType(t *testing.T) {
+	input := ":20:FIRST\n:25:ACCOUNT\n:28C:1/1\n:60F:C171011HUF1,00\n:62F:C171011HUF1,00\n-\n" +
+		":20:BROKEN\n:60F:C171011HUF1,00\n:62F:C171011HUF1,00\n-\n" +
+		":20:LAST\n:25:ACCOUNT\n:28C:2/1\n:60F:C171011HUF1,00\n"
+
+	if _, err := (&Transactions{}).Parse(strings.NewReader(input)); err != nil {
+		t.Fatalf("Transactions.Parse() without MessageType error = %v", err)
+	}
+
+	_, err := (&Transactions{MessageType: MT940}).Parse(strings.NewReader(input))
+	var te *TagError
+	if !errors.As(err, &te) || !errors.Is(err, ErrValidation) || te.Pos.Line != 8 {
+		t.Errorf("Transactions.Parse() error = %v, want a validation error on line 8", err)
+	}
+
+	tr := &Transactions{MessageType: MT940, Lenient: true}
+	got, err := tr.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || len(tr.Diagnostics) != 3 {
+		t.Errorf("Transactions.Parse() = %v transactions and diagnostics %v, want 3 and 3", len(got), tr.Diagnostics)
+	}
+
+	tr = &Transactions{MessageType: MT940, Recover: true}
+	if got, err = tr.Parse(strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].TransactionReferenceNumber != "FIRST" || len(tr.Skipped) != 2 {
+		t.Errorf("Transactions.Parse() = %v, skipped %v, want FIRST only", got, tr.Skipped)
+	}
+	if skipped := input[tr.Skipped[1].Pos.Offset:tr.Skipped[1].Pos.End]; !strings.HasPrefix(skipped, ":20:LAST") {
+		t.Errorf("Skipped[1] = %q, want the last statement", skipped)
+	}
+}
@@ -0,0 +1,281 @@
+package mt940
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/currency"
+)
+
+// Header of a written statement, the :25: and :28C: values
+type statementHeader struct {
+	account, number, sequence string
+}
+
+// Writes the statements as MT940 text with CRLF line endings, each followed
+// by a - separator. The values are checked against the SWIFT formats of
+// their tags, with Lenient the ones that don't fit are written as they are
+// and reported in Diagnostics. Statements without an account or statement
+// number take the ones of t
+func (t *Transactions) Write(w io.Writer, statements []Transaction) error {
+	for i := range statements {
+		tr := &statements[i]
+		header := statementHeader{tr.AccountIdentification, tr.StatementNumber, tr.StatementSeqNumber}
+		if header.account == "" {
+			header.account = t.AccountIdentification
+		}
+		if header.number == "" {
+			header.number, header.sequence = t.StatementNumber, t.StatementSeqNumber
+		}
+		diagnostics, err := writeStatement(w, tr, header, t.Lenient)
+		if err != nil {
+			return err
+		}
+		t.Diagnostics = append(t.Diagnostics, diagnostics...)
+	}
+	return nil
+}
+
+// Writes the statements of each account to its own writer, opened by create
+// with the :25: of the account. Accounts may contain characters like / which
+// create has to map when naming files
+func (t *Transactions) WriteAccounts(statements []Transaction, create func(account string) (io.WriteCloser, error)) error {
+	for _, a := range ByAccount(statements) {
+		account := a.Account
+		if account == "" {
+			account = t.AccountIdentification
+		}
+		w, err := create(account)
+		if err != nil {
+			return fmt.Errorf("account %v: %w", account, err)
+		}
+		err = t.Write(w, a.Statements)
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("account %v: %w", account, err)
+		}
+	}
+	return nil
+}
+
+// Collects the tags of a statement, failing with the first value that
+// doesn't fit its tag unless lenient, which reports them in diagnostics
+type tagWriter struct {
+	lines       []string
+	err         error
+	lenient     bool
+	diagnostics Errors
+}
+
+func (tw *tagWriter) tag(id string, r TagResults) {
+	if tw.err != nil {
+		return
+	}
+	format := Tags[id].format
+	if !tw.lenient {
+		value, err := format.Write(r)
+		if err != nil {
+			tw.err = fmt.Errorf("writing :%v: %w", id, err)
+			return
+		}
+		tw.raw(":" + id + ":" + value)
+		return
+	}
+	value, errs, err := format.WriteLenient(r)
+	if err != nil {
+		tw.err = fmt.Errorf("writing :%v: %w", id, err)
+		return
+	}
+	for _, e := range errs {
+		tw.diagnostics = append(tw.diagnostics, fmt.Errorf("writing :%v: %w", id, e))
+	}
+	tw.raw(":" + id + ":" + value)
+}
+
+func (tw *tagWriter) raw(text string) {
+	tw.lines = append(tw.lines, strings.Split(text, "\n")...)
+}
+
+func (tw *tagWriter) balance(id string, b *Balance) {
+	if tw.err != nil {
+		return
+	}
+	if b.Timestamp.Time == nil {
+		tw.err = fmt.Errorf("writing :%v: balance without a date", id)
+		return
+	}
+	code := balanceCode(b)
+	tw.tag(id, TagResults{
+		"status":   b.Status,
+		"year":     b.Timestamp.Format("06"),
+		"month":    b.Timestamp.Format("01"),
+		"day":      b.Timestamp.Format("02"),
+		"currency": code,
+		"amount":   b.Amount.format(minorUnits(code)),
+	})
+}
+
+func (tw *tagWriter) summary(id string, s *EntrySummary) {
+	code := s.Currency.String()
+	tw.tag(id, TagResults{
+		"number":   fmt.Sprint(s.Count),
+		"currency": code,
+		"amount":   s.Amount.format(minorUnits(code)),
+	})
+}
+
+func (tw *tagWriter) details(text string) {
+	if text != "" {
+		tw.tag("86", TagResults{"transaction_details": text})
+	}
+}
+
+// NonSwift doesn't keep the order of its codes, they're written sorted
+func (tw *tagWriter) nonSwift(ns NonSwift) {
+	if len(ns) == 0 {
+		return
+	}
+	codes := make([]string, 0, len(ns))
+	for code := range ns {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	var lines []string
+	for _, code := range codes {
+		for _, value := range ns[code] {
+			lines = append(lines, code+value)
+		}
+	}
+	tw.raw(":NS:" + strings.Join(lines, "\n"))
+}
+
+func balanceCode(b *Balance) string {
+	if b.code != "" {
+		return b.code
+	}
+	return b.Currency.String()
+}
+
+func minorUnits(code string) int {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return 2
+	}
+	scale, _ := currency.Standard.Rounding(unit)
+	return scale
+}
+
+func writeStatement(w io.Writer, tr *Transaction, header statementHeader, lenient bool) (Errors, error) {
+	tw := &tagWriter{lenient: lenient}
+	tw.tag("20", TagResults{"transaction_reference": tr.TransactionReferenceNumber})
+	if tr.RelatedReference != "" {
+		tw.tag("21", TagResults{"related_reference": tr.RelatedReference})
+	}
+	tw.tag("25", TagResults{"account_identification": header.account})
+	tw.tag("28C", TagResults{"statement_number": header.number, "sequence_number": header.sequence})
+	if fl := tr.FloorLimit; fl != nil {
+		code := fl.Currency.String()
+		if fl.Debit == fl.Credit {
+			tw.tag("34F", TagResults{"currency": code, "amount": fl.Debit.format(minorUnits(code))})
+		} else {
+			tw.tag("34F", TagResults{"currency": code, "status": "D", "amount": fl.Debit.format(minorUnits(code))})
+			tw.tag("34F", TagResults{"currency": code, "status": "C", "amount": fl.Credit.format(minorUnits(code))})
+		}
+	}
+	if created := tr.DateTimeIndication; created != nil {
+		offset := created.Format("-0700")
+		tw.tag("13D", TagResults{
+			"year":   created.Format("06"),
+			"month":  created.Format("01"),
+			"day":    created.Format("02"),
+			"hour":   created.Format("15"),
+			"minute": created.Format("04"),
+			"sign":   offset[:1],
+			"offset": offset[1:],
+		})
+	}
+	if tr.FinalOpeningBalance.Timestamp.Time == nil && tr.IntermediateOpeningBalance.Timestamp.Time != nil {
+		tw.balance("60M", &tr.IntermediateOpeningBalance)
+	} else {
+		tw.balance("60F", &tr.FinalOpeningBalance)
+	}
+	tw.details(tr.HeaderInformation)
+	tw.nonSwift(tr.NonSwift)
+
+	code := balanceCode(&tr.FinalOpeningBalance)
+	if code == "" {
+		code = balanceCode(&tr.IntermediateOpeningBalance)
+	}
+	for i := range tr.Entries {
+		e := &tr.Entries[i]
+		if tw.err != nil {
+			break
+		}
+		if e.Timestamp.Time == nil {
+			tw.err = fmt.Errorf("writing :61: of entry %v without a value date", i+1)
+			break
+		}
+		if len(e.TransactionTypeID) != 4 {
+			tw.err = fmt.Errorf("writing :61: of entry %v with transaction type %q", i+1, e.TransactionTypeID)
+			break
+		}
+		r := TagResults{
+			"year":               e.Timestamp.Format("06"),
+			"month":              e.Timestamp.Format("01"),
+			"day":                e.Timestamp.Format("02"),
+			"status":             e.Status,
+			"funds_code":         e.FundsCode,
+			"amount":             e.Amount.format(minorUnits(code)),
+			"id_class":           e.TransactionTypeID[:1],
+			"id_code":            e.TransactionTypeID[1:],
+			"customer_reference": e.CustomerReference,
+			"bank_reference":     e.BankReference,
+			"extra_details":      e.ExtraDetails,
+		}
+		if e.EntryTime.Time != nil {
+			r["entry_month"] = e.EntryTime.Format("01")
+			r["entry_day"] = e.EntryTime.Format("02")
+		}
+		if r["customer_reference"] == "" {
+			r["customer_reference"] = "NONREF"
+		}
+		tw.tag("61", r)
+		tw.details(e.TransactionDetails)
+		tw.nonSwift(e.NonSwift)
+	}
+
+	if tr.FinalClosingBalance.Timestamp.Time == nil && tr.IntermediateClosingBalance.Timestamp.Time != nil {
+		tw.balance("62M", &tr.IntermediateClosingBalance)
+	} else {
+		tw.balance("62F", &tr.FinalClosingBalance)
+	}
+	if tr.AvailableBalance.Timestamp.Time != nil {
+		tw.balance("64", &tr.AvailableBalance)
+	}
+	for i := range tr.ForwardAvailableBalance {
+		tw.balance("65", &tr.ForwardAvailableBalance[i])
+	}
+	if tr.DebitEntries != nil {
+		tw.summary("90D", tr.DebitEntries)
+	}
+	if tr.CreditEntries != nil {
+		tw.summary("90C", tr.CreditEntries)
+	}
+	tw.details(tr.Information)
+	for _, tags := range [][]ExtraTag{tr.Extra, tr.Unknown} {
+		for _, tag := range tags {
+			tw.raw(tag.Value)
+		}
+	}
+	if tw.err != nil {
+		return nil, tw.err
+	}
+
+	tw.lines = append(tw.lines, "-")
+	_, err := io.WriteString(w, strings.Join(tw.lines, "\r\n")+"\r\n")
+	return tw.diagnostics, err
+}
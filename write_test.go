@@ -0,0 +1,262 @@
+package mt940
+
+import (
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransactions_Write(t *testing.T) {
+	ts := &Transactions{}
+	got, err := ts.Parse(strings.NewReader(editInput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := ts.Write(&b, got); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.ReplaceAll(editInput, "\n", "\r\n") + "-\r\n"
+	if b.String() != want {
+		t.Errorf("Transactions.Write() = %q, want %q", b.String(), want)
+	}
+
+	// Edits survive writing and parsing again
+	date := time.Date(2017, time.October, 12, 0, 0, 0, 0, time.UTC)
+	got[0].AddEntry(Entry{
+		StatementLine: StatementLine{
+			Timestamp: TransactionDate{&date}, EntryTime: TransactionDate{&date},
+			Status: "C", Amount: NewAmount(1234), TransactionTypeID: "NMSC", BankReference: "BANK1",
+		},
+		TransactionDetails: "two\nlines",
+	})
+	b.Reset()
+	if err := ts.Write(&b, got); err != nil {
+		t.Fatal(err)
+	}
+	reparsed, err := (&Transactions{}).Parse(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := reparsed[0].Entries[3]
+	if e.Amount != NewAmount(1234) || e.CustomerReference != "NONREF" || e.BankReference != "BANK1" ||
+		e.TransactionDetails != "two\nlines" || !e.EntryTime.Equal(date) {
+		t.Errorf("written entry = %+v", e)
+	}
+	if c := reparsed[0].FinalClosingBalance; c.Status != "C" || c.Amount != NewAmount(9884) {
+		t.Errorf("written closing balance = %v%v, want C98,84", c.Status, c.Decimal())
+	}
+}
+
+func TestTransactions_Write_ForwardAvailableBalance(t *testing.T) {
+	input := ":20:REF\n:25:NL08DEUT0319809633\n:28C:1\n:60F:C171011EUR1,00\n:62F:C171011EUR1,00\n:64:C171011EUR1,00\n:65:C171012EUR1,00\n:65:C171013EUR2,00\n:86:information\n"
+	ts := &Transactions{}
+	got, err := ts.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := ts.Write(&b, got); err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.ReplaceAll(input, "\n", "\r\n") + "-\r\n"; b.String() != want {
+		t.Errorf("Transactions.Write() = %q, want %q", b.String(), want)
+	}
+}
+
+func TestTransactions_Write_DateTimeIndication(t *testing.T) {
+	input := ":20:REF\n:25:NL08DEUT0319809633\n:28C:1\n:34F:PLN0,00\n:13D:1701191815+0100\n:60F:C170119PLN1,00\n:62F:C170119PLN1,00\n"
+	ts := &Transactions{}
+	got, err := ts.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := ts.Write(&b, got); err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.ReplaceAll(input, "\n", "\r\n") + "-\r\n"; b.String() != want {
+		t.Errorf("Transactions.Write() = %q, want %q", b.String(), want)
+	}
+}
+
+func TestTransactions_Write_MinorUnits(t *testing.T) {
+	input := ":20:REF\n:25:NL08DEUT0319809633\n:28C:1\n:60F:C171011BHD1,125\n" +
+		":61:171011D0,005NTRFNONREF\n:62F:C171011BHD1,12\n:64:C171011JPY100,\n"
+	ts := &Transactions{}
+	got, err := ts.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := ts.Write(&b, got); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.ReplaceAll(strings.Replace(input, "BHD1,12\n", "BHD1,120\n", 1), "\n", "\r\n") + "-\r\n"
+	if b.String() != want {
+		t.Errorf("Transactions.Write() = %q, want %q", b.String(), want)
+	}
+}
+
+func TestTransactions_WriteAccounts(t *testing.T) {
+	ts := &Transactions{}
+	got, err := ts.Parse(strings.NewReader(stitchPage1 + stitchOther + stitchPage2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string]*closingBuilder)
+	if err := ts.WriteAccounts(got, func(account string) (io.WriteCloser, error) {
+		files[account] = &closingBuilder{}
+		return files[account], nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"NL08DEUT0319809633": stitchPage1 + stitchPage2,
+		"DK0230003617012345": stitchOther,
+	}
+	if len(files) != len(want) {
+		t.Fatalf("Transactions.WriteAccounts() wrote %v files, want %v", len(files), len(want))
+	}
+	for account, input := range want {
+		f := files[account]
+		if f == nil || !f.closed || f.String() != strings.ReplaceAll(input, "\n", "\r\n") {
+			t.Errorf("Transactions.WriteAccounts() wrote %v: %+v", account, f)
+		}
+	}
+
+	fail := errors.New("refused")
+	if err := ts.WriteAccounts(got, func(account string) (io.WriteCloser, error) { return nil, fail }); !errors.Is(err, fail) {
+		t.Errorf("Transactions.WriteAccounts() error = %v, want %v", err, fail)
+	}
+}
+
+type closingBuilder struct {
+	strings.Builder
+	closed bool
+}
+
+func (b *closingBuilder) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestTransactions_Write_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(tr *Transaction)
+	}{
+		{"long reference", func(tr *Transaction) { tr.TransactionReferenceNumber = "12345678901234567" }},
+		{"details charset", func(tr *Transaction) { tr.SetEntryDetails(0, "{not allowed}") }},
+		{"transaction type", func(tr *Transaction) { tr.Entries[0].TransactionTypeID = "" }},
+		{"no opening balance", func(tr *Transaction) { tr.FinalOpeningBalance = Balance{} }},
+		{"entry without a date", func(tr *Transaction) { tr.Entries[0].Timestamp = TransactionDate{} }},
+		{"zero entry", func(tr *Transaction) { tr.Entries = append(tr.Entries, Entry{}) }},
+		{"closing balance without a date", func(tr *Transaction) {
+			tr.TransactionReferenceNumber = "12345678901234567"
+			tr.FinalClosingBalance.Timestamp = TransactionDate{}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := &Transactions{}
+			got, err := ts.Parse(strings.NewReader(editInput))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.edit(&got[0])
+			var b strings.Builder
+			if err := ts.Write(&b, got); err == nil || b.Len() != 0 {
+				t.Errorf("Transactions.Write() error = %v, wrote %q", err, b.String())
+			}
+		})
+	}
+
+	// Nothing to write doesn't panic
+	if err := (&Transactions{}).Write(&strings.Builder{}, []Transaction{{}}); err == nil {
+		t.Error("Transactions.Write() of a zero Transaction succeeded")
+	}
+
+	var fe *FieldError
+	ts := &Transactions{}
+	got, _ := ts.Parse(strings.NewReader(editInput))
+	got[0].TransactionReferenceNumber = "12345678901234567"
+	if err := ts.Write(&strings.Builder{}, got); !errors.As(err, &fe) || fe.Field != "transaction_reference" {
+		t.Errorf("Transactions.Write() error = %v, want a FieldError", err)
+	}
+}
+
+func TestTransactions_Write_Fixtures(t *testing.T) {
+	files := []string{
+		"ASNB/0708271685_09022020_164516.940.txt",
+		"betterplace/sepa_mt9401.sta",
+		"betterplace/sepa_snippet.sta",
+		"betterplace/with_binary_character.sta",
+		"jejik/ing.sta",
+		"jejik/knab.sta",
+		"jejik/postfinance.sta",
+		"jejik/rabobank-iban.sta",
+		"jejik/sns.sta",
+		"mBank/mt940.sta",
+		"mBank/with_newline_in_tnr.sta",
+		"self-provided/details_60-63.sta",
+		"self-provided/overly_long_details.sta",
+		"self-provided/raiffeisen-cmi.sta",
+		"self-provided/whitespace.sta",
+	}
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			got, err := (&Transactions{Lenient: true, AutoDetectEncoding: true}).Parse(must(os.Open(file)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b strings.Builder
+			ts := &Transactions{Lenient: true}
+			if err := ts.Write(&b, got); err != nil {
+				t.Fatal(err)
+			}
+			reparsed, err := (&Transactions{Lenient: true}).Parse(strings.NewReader(b.String()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(reparsed) != len(got) {
+				t.Fatalf("Transactions.Parse() of the written file = %v statements, want %v", len(reparsed), len(got))
+			}
+			// Entries without a reference are written with NONREF
+			nonref := func(sl *StatementLine) {
+				if sl.CustomerReference == "" && sl.TransactionTypeID != "" {
+					sl.CustomerReference = "NONREF"
+				}
+			}
+			for i := range got {
+				if len(reparsed[i].Entries) != len(got[i].Entries) {
+					t.Fatalf("statement %v has %v entries, want %v", i, len(reparsed[i].Entries), len(got[i].Entries))
+				}
+				want := summarize(got[i])
+				nonref(&want.StatementLine)
+				for j := range want.Entries {
+					nonref(&want.Entries[j].StatementLine)
+				}
+				if s := summarize(reparsed[i]); !reflect.DeepEqual(s, want) {
+					t.Errorf("statement %v = %v %v%v %q, want %v %v%v %q", i, s.TransactionReferenceNumber, s.Status, s.Decimal(), s.TransactionDetails,
+						want.TransactionReferenceNumber, want.Status, want.Decimal(), want.TransactionDetails)
+				}
+			}
+		})
+	}
+
+	// Strict writing refuses the values outside of the SWIFT formats
+	got, err := (&Transactions{}).Parse(must(os.Open("mBank/mt940.sta")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Transactions{}).Write(&strings.Builder{}, got); !errors.Is(err, ErrFormatMismatch) {
+		t.Errorf("Transactions.Write() error = %v, want %v", err, ErrFormatMismatch)
+	}
+}